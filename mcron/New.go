@@ -1,10 +1,9 @@
 package mcron
 
 import (
+	"context"
 	"errors"
-	"fmt"
-
-	"github.com/robfig/cron/v3"
+	"time"
 )
 
 /*
@@ -12,8 +11,10 @@ Cron在线表达式生成器: https://cron.ciding.cc/
 
 Package m_cron 提供一个简易的定时任务封装，基于 github.com/robfig/cron/v3
 默认使用带秒字段的解析器（cron.WithSeconds()）。
-注意：robfig/cron 不完全兼容 Quartz 的一些扩展语法（如 '?'、L、W、# 等）。
-若需兼容 Quartz 风格表达式，建议在调用方做预处理或使用专门支持 Quartz 的库。
+robfig/cron 本身不支持 Quartz 的扩展语法（L、W、# 以及第 7 个年字段），
+本包会自动识别这类表达式并交给 ParseQuartz 解析；也可以设置 CronOption.Quartz 强制按 Quartz 语义解析，详见 quartz.go。
+
+New 适合只有一个任务的场景；需要在同一个调度器上托管多个任务时使用 NewScheduler。
 
 示例：
 
@@ -28,7 +29,7 @@ Package m_cron 提供一个简易的定时任务封装，基于 github.com/robfi
 	defer c.Stop()
 */
 var (
-	// ErrNilFunc 表示未提供要执行的函数。
+	// ErrNilFunc 表示未提供要执行的函数（Func 与 Task 均为空）。
 	ErrNilFunc = errors.New("m_cron: Func is nil")
 	// ErrEmptySpec 表示未提供 cron 表达式。
 	ErrEmptySpec = errors.New("m_cron: Spec is empty")
@@ -37,65 +38,157 @@ var (
 // CronOption 是 New 的配置项。
 type CronOption struct {
 	Func      func() // 定时执行的函数，不能为空
-	Spec      string // cron 表达式（带秒时为 6 字段），不能为空
+	Spec      string // cron 表达式（带秒 6 字段，或 Quartz 6/7 字段），不能为空
 	Immediate bool   // 是否在启动后立即执行一次
+
+	// Quartz 为 true 时总是按 Quartz 语义解析 Spec（周字段 1 表示周日），
+	// 否则只有 7 个字段或使用了 L、W、# 的表达式才按 Quartz 解析，见 IsQuartzSpec；
+	// 日字段为 '?' 且周字段使用数字时两种编号无法区分，需设置此项，否则返回错误。
+	Quartz bool
+
+	// Overlap 上一次执行尚未结束时再次触发的处理策略，默认 OverlapAllow。
+	// 被跳过的次数可通过 Cron.Skipped 或 Scheduler.List 查看，便于对慢任务告警。
+	Overlap OverlapPolicy
+
+	// Task 是可返回错误的任务函数，设置后优先于 Func 执行；Func 与 Task 至少提供一个。
+	// 任务 panic 会被恢复为 *PanicError（含堆栈），与返回的错误一同交给 OnError。
+	// ctx 在超过 Timeout 或 StopContext 等待超时后被取消，任务应据此尽快返回。
+	Task func(ctx context.Context) error
+
+	OnError     Hook // 执行失败（返回错误或 panic）以及 Locker、Store 出错时的回调，为空时使用调度器默认值
+	OnSuccess   Hook // 执行成功后的回调，为空时使用调度器默认值
+	HistorySize int  // 保留的执行记录条数，<=0 时为 20
+
+	// Misfire 启动时对错过的触发的补偿策略，默认 MisfireIgnore。
+	// 需要配合 Store 使用：根据上次成功执行的时间计算启动前错过的触发次数。
+	// 补跑与 Immediate 相互独立。
+	Misfire MisfirePolicy
+	Store   StateStore // 状态存储，为空时使用调度器的 Store
+
+	Locker Locker // 跨进程锁，为空时使用调度器的 Locker
+
+	// Location 按哪个时区解释表达式，为空时使用表达式的 CRON_TZ= 前缀或调度器的 Location。
+	// 与 CRON_TZ= 前缀同时设置且不一致时返回错误。夏令时的处理见 location.go。
+	Location *time.Location
+
+	// Timeout 单次执行的超时时间，<=0 表示不限制。超时后传给 Task 的 ctx 会被取消，
+	// 任务需要自行响应 ctx 才能提前结束；Func 不接收 ctx，不受此项影响。
+	Timeout time.Duration
+
+	// Clock 是 New 创建的内部调度器使用的时间源，为空时使用系统时间；测试中可传入 NewFakeClock。
+	// 通过 Scheduler.Add 注册时忽略此项，以 SchedulerOption.Clock 为准。
+	Clock Clock
 }
 
-// Cron 是本包对 github.com/robfig/cron 的封装，便于管理任务与优雅停止。
+// defaultJobName 是 New 在内部调度器上注册任务时使用的名称。
+const defaultJobName = "default"
+
+// Cron 是 New 返回的单任务句柄，内部持有一个只包含该任务的 Scheduler。
+// 需要托管多个任务时请直接使用 NewScheduler。
 type Cron struct {
-	c     *cron.Cron
-	entry cron.EntryID
+	s    *Scheduler
+	name string
 }
 
 // New 创建并启动定时任务并返回封装的 *Cron。
 // 当参数不合法或表达式无法解析时返回错误。返回的 Cron 需要在适当时机 Stop()。
 func New(opt CronOption) (*Cron, error) {
-	if opt.Func == nil {
-		return nil, ErrNilFunc
-	}
-	if opt.Spec == "" {
-		return nil, ErrEmptySpec
-	}
-
-	c := cron.New(cron.WithSeconds())
-
-	id, err := c.AddFunc(opt.Spec, opt.Func)
-	if err != nil {
-		return nil, fmt.Errorf("m_cron: invalid spec %q: %w", opt.Spec, err)
+	if _, err := parseOption(opt); err != nil {
+		return nil, err
 	}
 
-	c.Start()
-
-	if opt.Immediate {
-		// 非阻塞立即执行一次
-		go func() {
-			defer func() {
-				// 防止用户函数 panic 影响调度器
-				_ = recover()
-			}()
-			opt.Func()
-		}()
+	s := NewScheduler(SchedulerOption{Clock: opt.Clock})
+	if err := s.Add(defaultJobName, opt); err != nil {
+		s.Stop()
+		return nil, err
 	}
 
 	return &Cron{
-		c:     c,
-		entry: id,
+		s:    s,
+		name: defaultJobName,
 	}, nil
 }
 
 // Stop 优雅停止并等待正在运行的任务完成。
 func (cr *Cron) Stop() {
-	if cr == nil || cr.c == nil {
+	if cr == nil || cr.s == nil {
 		return
 	}
-	ctx := cr.c.Stop()
-	<-ctx.Done()
+	cr.s.Stop()
 }
 
-// Remove 删除已注册的任务（若需要按 ID 删除）。
+// StopContext 停止调度并最多等待到 ctx 结束，超时后取消正在执行的 Task 的 ctx，
+// 详见 Scheduler.StopContext。
+func (cr *Cron) StopContext(ctx context.Context) error {
+	if cr == nil || cr.s == nil {
+		return nil
+	}
+	return cr.s.StopContext(ctx)
+}
+
+// Remove 删除已注册的任务，调度器本身仍需 Stop()。
 func (cr *Cron) Remove() {
-	if cr == nil || cr.c == nil {
+	if cr == nil || cr.s == nil {
 		return
 	}
-	cr.c.Remove(cr.entry)
+	_ = cr.s.RemoveJob(cr.name)
+}
+
+// Pause 暂停任务，暂停期间到期的触发会被丢弃，恢复后不补跑。
+func (cr *Cron) Pause() {
+	if cr == nil || cr.s == nil {
+		return
+	}
+	_ = cr.s.Pause(cr.name)
+}
+
+// Resume 恢复已暂停的任务。
+func (cr *Cron) Resume() {
+	if cr == nil || cr.s == nil {
+		return
+	}
+	_ = cr.s.Resume(cr.name)
+}
+
+// IsPaused 返回任务是否已暂停。
+func (cr *Cron) IsPaused() bool {
+	if cr == nil || cr.s == nil {
+		return false
+	}
+	paused, _ := cr.s.IsPaused(cr.name)
+	return paused
+}
+
+// RunNow 在后台立即执行一次任务，遵循与定时触发相同的重叠策略与 panic 保护。
+// 任务已 Stop 或 Remove 时返回错误。
+func (cr *Cron) RunNow() error {
+	if cr == nil || cr.s == nil {
+		return ErrStopped
+	}
+	return cr.s.RunNow(cr.name)
+}
+
+// Skipped 返回任务因重叠策略被跳过的触发次数。
+func (cr *Cron) Skipped() int64 {
+	if cr == nil || cr.s == nil {
+		return 0
+	}
+	return cr.s.skipped(cr.name)
+}
+
+// LockSkipped 返回任务因其他实例持有 Locker 租约而跳过的次数。
+func (cr *Cron) LockSkipped() int64 {
+	if cr == nil || cr.s == nil {
+		return 0
+	}
+	return cr.s.lockSkipped(cr.name)
+}
+
+// History 返回任务最近的执行记录，按时间先后排列。
+func (cr *Cron) History() []RunRecord {
+	if cr == nil || cr.s == nil {
+		return nil
+	}
+	runs, _ := cr.s.History(cr.name)
+	return runs
 }
@@ -0,0 +1,135 @@
+package mcron
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock 是调度器使用的时间源，测试时可替换为 FakeClock 以避免真实等待。
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer 是 Clock 创建的定时器。
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// realClock 基于标准库 time 实现 Clock。
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+// realTimer 包装 *time.Timer。
+type realTimer struct{ t *time.Timer }
+
+func (r realTimer) C() <-chan time.Time { return r.t.C }
+
+func (r realTimer) Stop() bool { return r.t.Stop() }
+
+/*
+FakeClock 是只在 Advance 时才前进的 Clock，用于确定性地测试定时任务。
+
+	clock := mcron.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := mcron.NewScheduler(mcron.SchedulerOption{Clock: clock})
+	defer s.Stop()
+
+	_ = s.AddJob("tick", "@every 1m", func() { done <- struct{}{} })
+	clock.BlockUntil(1)         // 等待调度器挂上定时器
+	clock.Advance(time.Minute)  // 立即触发
+	<-done
+
+调度器与 robfig/cron 一样根据当前时间计算下一次触发，一次 Advance 跨过多个触发点时只会执行一次，
+需要逐次触发时请配合 BlockUntil 分步 Advance。
+*/
+type FakeClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFakeClock 创建一个当前时间为 now 的 FakeClock。
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now 实现 Clock。
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer 实现 Clock，d <= 0 时定时器立即到期。
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, at: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	return t
+}
+
+// Advance 让时间前进 d，并按到期先后触发期间到期的定时器。
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	sort.SliceStable(c.timers, func(i, k int) bool { return c.timers[i].at.Before(c.timers[k].at) })
+	n := 0
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			c.timers[n] = t
+			n++
+			continue
+		}
+		t.ch <- t.at
+	}
+	c.timers = c.timers[:n]
+	c.cond.Broadcast()
+}
+
+// BlockUntil 阻塞直到至少有 n 个未到期的定时器，用于等待调度器完成下一轮挂起。
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// fakeTimer 是 FakeClock 创建的定时器。
+type fakeTimer struct {
+	clock *FakeClock
+	at    time.Time
+	ch    chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.ch }
+
+// Stop 实现 Timer，返回定时器是否在到期前被停止。
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, other := range c.timers {
+		if other == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
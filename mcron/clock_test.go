@@ -0,0 +1,57 @@
+package mcron
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFakeClock 测试 FakeClock 驱动调度器逐次触发
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	s := NewScheduler(SchedulerOption{Clock: clock, Location: time.UTC})
+	defer s.Stop()
+
+	ticks := make(chan time.Time, 10)
+	err := s.Add("minutely", CronOption{
+		Spec:      "0 * * * * *",
+		Func:      func() {},
+		OnSuccess: func(name string, rec RunRecord) { ticks <- rec.Start },
+	})
+	if err != nil {
+		t.Fatalf("Add error = %v", err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+		select {
+		case got := <-ticks:
+			if want := start.Add(time.Duration(i) * time.Minute); !got.Equal(want) {
+				t.Errorf("第 %d 次执行时间 = %v, want %v", i, got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("第 %d 次 Advance 后任务未执行", i)
+		}
+	}
+
+	info := s.List()[0]
+	if want := start.Add(3 * time.Minute); !info.Prev.Equal(want) {
+		t.Errorf("Prev = %v, want %v", info.Prev, want)
+	}
+	if want := start.Add(4 * time.Minute); !info.Next.Equal(want) {
+		t.Errorf("Next = %v, want %v", info.Next, want)
+	}
+	if h, err := s.History("minutely"); err != nil || len(h) != 3 {
+		t.Errorf("History = %d, %v, want 3, nil", len(h), err)
+	}
+
+	// 未到触发时间不执行
+	clock.BlockUntil(1)
+	clock.Advance(30 * time.Second)
+	select {
+	case got := <-ticks:
+		t.Errorf("提前执行于 %v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
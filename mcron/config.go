@@ -0,0 +1,356 @@
+package mcron
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/m-startgo/go-utils/mfile"
+	"github.com/m-startgo/go-utils/mjson"
+)
+
+/*
+Loader 从 JSON 文件读取任务定义，按名称绑定到注册过的函数并托管到 Scheduler 上，
+文件变化后自动新增、删除或重新调度任务，运维修改调度无需重新发布。
+
+配置文件格式：
+
+	{
+		"jobs": [
+			{"name": "report", "spec": "0 0 12 * * *", "timezone": "Asia/Shanghai", "timeout": "5m"},
+			{"name": "clean", "spec": "@every 10m", "func": "cleanup", "enabled": false}
+		]
+	}
+
+func 为空时按 name 查找函数；enabled 缺省为 true，为 false 时任务保留注册但处于暂停状态；
+timeout 使用 time.ParseDuration 的格式。
+
+示例：
+
+	s := mcron.NewScheduler(mcron.SchedulerOption{})
+	defer s.Stop()
+
+	l := mcron.NewLoader(mcron.LoaderOption{
+		Path:      "./cron.json",
+		Scheduler: s,
+		OnError:   func(err error) { log.Println(err) },
+	})
+	l.Register("report", report)
+	l.Register("cleanup", cleanup)
+	if err := l.Watch(); err != nil {
+		log.Println(err) // 有误的任务不会生效，其余任务照常加载
+	}
+	defer l.Stop()
+
+某个任务的定义有误（表达式非法、函数未注册、时区不存在等）时只报告错误，
+该任务保持上一次生效的配置继续运行，不影响其他任务；文件整体无法解析时不做任何改动。
+因函数未注册而未生效的任务会在之后 Register 对应函数时自动加载。
+Loader 只管理由它加载的任务，不会删除通过代码直接添加到 Scheduler 上的任务。
+
+Watch 发现文件内容变化后，要等到连续两次检查读到相同内容才会加载，避免读到写了一半的文件；
+写入方仍建议先写临时文件再重命名。
+*/
+
+// ErrFuncNotFound 表示配置引用的函数未通过 Loader.Register 注册。
+var ErrFuncNotFound = errors.New("m_cron: func not registered")
+
+// defaultWatchInterval 是未配置 Interval 时检查配置文件的间隔。
+const defaultWatchInterval = 2 * time.Second
+
+// JobConfig 是配置文件中的单个任务定义。
+type JobConfig struct {
+	Name     string `json:"name"`     // 任务名称，不能为空且不能重复
+	Spec     string `json:"spec"`     // cron 表达式，语法与 CronOption.Spec 相同
+	Func     string `json:"func"`     // 绑定的函数名称，为空时使用 Name
+	Enabled  *bool  `json:"enabled"`  // 是否启用，缺省为 true
+	Timezone string `json:"timezone"` // 时区，例如 "Asia/Shanghai"，对应 CronOption.Location
+	Timeout  string `json:"timeout"`  // 单次执行超时，例如 "30s"，对应 CronOption.Timeout
+	Quartz   bool   `json:"quartz"`   // 是否强制按 Quartz 语义解析，对应 CronOption.Quartz
+}
+
+// enabled 返回任务是否启用。
+func (c JobConfig) enabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// sameSchedule 判断两个定义除 enabled 外是否相同，相同时无需重新调度。
+func (c JobConfig) sameSchedule(o JobConfig) bool {
+	return c.Spec == o.Spec && c.Func == o.Func && c.Timezone == o.Timezone && c.Timeout == o.Timeout && c.Quartz == o.Quartz
+}
+
+// configFile 是配置文件的顶层结构。
+type configFile struct {
+	Jobs []JobConfig `json:"jobs"`
+}
+
+// LoaderOption 是 NewLoader 的配置项。
+type LoaderOption struct {
+	Path      string     // 配置文件路径
+	Scheduler *Scheduler // 任务加载到的调度器
+
+	Interval time.Duration   // Watch 检查文件变化的间隔，<=0 时为 2s
+	OnError  func(err error) // Watch 期间重新加载出错、或 Register 后补加载出错时的回调
+}
+
+// Loader 按配置文件管理 Scheduler 上的任务，见文件开头的说明。
+type Loader struct {
+	opt LoaderOption
+
+	mu      sync.Mutex
+	funcs   map[string]func(ctx context.Context) error
+	applied map[string]JobConfig // 由 Loader 加载且当前生效的定义
+	waiting map[string]JobConfig // 因函数未注册而未生效的定义，Register 后重试
+	content []byte               // 最近一次成功解析的文件内容
+	changed []byte               // Watch 读到的与 content 不同的内容，再次读到相同内容时才加载
+	invalid []byte               // 最近一次无法解析的文件内容，内容不变时不重复报告
+	lastErr string               // 最近一次报告过的读取错误，避免重复报告
+	started bool
+
+	stopCh   chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewLoader 创建一个 Loader，需要先 Register 函数再 Load 或 Watch。
+func NewLoader(opt LoaderOption) *Loader {
+	if opt.Interval <= 0 {
+		opt.Interval = defaultWatchInterval
+	}
+	return &Loader{
+		opt:     opt,
+		funcs:   map[string]func(ctx context.Context) error{},
+		applied: map[string]JobConfig{},
+		waiting: map[string]JobConfig{},
+		stopCh:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Register 注册可被配置文件引用的函数，同名时覆盖。
+// 覆盖不会影响已加载的任务，直到其定义发生变化被重新调度；
+// 此前因该函数未注册而未生效的任务会立即加载，出错时交给 OnError。
+func (l *Loader) Register(name string, fn func(ctx context.Context) error) {
+	l.mu.Lock()
+	l.funcs[name] = fn
+	var errs []error
+	if s := l.opt.Scheduler; s != nil {
+		for job, cfg := range l.waiting {
+			err := l.applyJob(s, cfg)
+			if errors.Is(err, ErrFuncNotFound) {
+				continue
+			}
+			delete(l.waiting, job)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: job %q: %w", l.opt.Path, job, err))
+			}
+		}
+	}
+	l.mu.Unlock()
+	if err := errors.Join(errs...); err != nil && l.opt.OnError != nil {
+		l.opt.OnError(err)
+	}
+}
+
+// Load 读取配置文件并同步到 Scheduler，返回全部无效定义合并后的错误。
+func (l *Loader) Load() error {
+	b, err := mfile.Read(l.opt.Path)
+	if err != nil {
+		return fmt.Errorf("m_cron: read config: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.apply(b)
+}
+
+// Watch 先执行一次 Load，然后在后台定期检查文件，内容变化时重新加载。
+// 首次加载出错时仍会继续监听，便于修正配置后自动生效；重复调用只会启动一次监听。
+// 调度器 Stop 后监听自动结束。
+func (l *Loader) Watch() error {
+	if l.opt.Scheduler == nil {
+		return errors.New("m_cron: Loader Scheduler is nil")
+	}
+	err := l.Load()
+	l.mu.Lock()
+	if !l.started {
+		l.started = true
+		go l.watch()
+	}
+	l.mu.Unlock()
+	return err
+}
+
+// Stop 停止监听并等待后台 goroutine 退出，已加载的任务不受影响，重复调用是安全的。
+func (l *Loader) Stop() {
+	l.stopOnce.Do(func() { close(l.stopCh) })
+	l.mu.Lock()
+	started := l.started
+	l.mu.Unlock()
+	if started {
+		<-l.done
+	}
+}
+
+// watch 是 Watch 启动的后台轮询。
+func (l *Loader) watch() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.opt.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stopCh:
+			return
+		case <-l.opt.Scheduler.done:
+			return
+		case <-ticker.C:
+			if err := l.reload(); err != nil && l.opt.OnError != nil {
+				l.opt.OnError(err)
+			}
+		}
+	}
+}
+
+// reload 在文件内容变化且连续两次读取一致时重新加载，同一个读取错误或解析错误只报告一次。
+func (l *Loader) reload() error {
+	b, err := mfile.Read(l.opt.Path)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		if err.Error() == l.lastErr {
+			return nil
+		}
+		l.lastErr = err.Error()
+		return fmt.Errorf("m_cron: read config: %w", err)
+	}
+	l.lastErr = ""
+	if l.content != nil && bytes.Equal(b, l.content) || l.invalid != nil && bytes.Equal(b, l.invalid) {
+		l.changed = nil
+		return nil
+	}
+	if l.changed == nil || !bytes.Equal(b, l.changed) {
+		// 文件可能仍在写入，等下一次检查确认内容已稳定
+		l.changed = b
+		return nil
+	}
+	l.changed = nil
+	return l.apply(b)
+}
+
+// apply 将文件内容同步到调度器，调用方需持有 l.mu。
+func (l *Loader) apply(b []byte) error {
+	s := l.opt.Scheduler
+	if s == nil {
+		return errors.New("m_cron: Loader Scheduler is nil")
+	}
+
+	var f configFile
+	if err := mjson.Unmarshal(b, &f); err != nil {
+		l.invalid = b
+		return fmt.Errorf("m_cron: parse config %s: %w", l.opt.Path, err)
+	}
+	l.content, l.invalid = b, nil
+	clear(l.waiting)
+
+	var errs []error
+	seen := map[string]bool{}
+	for _, cfg := range f.Jobs {
+		if cfg.Name == "" {
+			errs = append(errs, fmt.Errorf("%s: %w", l.opt.Path, ErrEmptyName))
+			continue
+		}
+		if seen[cfg.Name] {
+			errs = append(errs, fmt.Errorf("%s: job %q: %w", l.opt.Path, cfg.Name, ErrJobExists))
+			continue
+		}
+		seen[cfg.Name] = true
+		if err := l.applyJob(s, cfg); err != nil {
+			if errors.Is(err, ErrFuncNotFound) {
+				l.waiting[cfg.Name] = cfg
+			}
+			errs = append(errs, fmt.Errorf("%s: job %q: %w", l.opt.Path, cfg.Name, err))
+		}
+	}
+	for name := range l.applied {
+		if seen[name] {
+			continue
+		}
+		if err := s.RemoveJob(name); err != nil && !errors.Is(err, ErrJobNotFound) {
+			errs = append(errs, fmt.Errorf("%s: job %q: %w", l.opt.Path, name, err))
+			continue
+		}
+		delete(l.applied, name)
+	}
+	return errors.Join(errs...)
+}
+
+// applyJob 新增或更新单个任务，定义无效时保持原任务不变。
+func (l *Loader) applyJob(s *Scheduler, cfg JobConfig) error {
+	if cfg.Func == "" {
+		cfg.Func = cfg.Name
+	}
+	opt, err := l.option(cfg)
+	if err != nil {
+		return err
+	}
+	// 先校验表达式，避免在无效定义上改动暂停状态
+	if _, err := parseOption(opt); err != nil {
+		return err
+	}
+
+	old, ok := l.applied[cfg.Name]
+	if !ok {
+		if err := s.add(cfg.Name, opt, !cfg.enabled()); err != nil {
+			return err
+		}
+		l.applied[cfg.Name] = cfg
+		return nil
+	}
+
+	// 禁用时先暂停再替换、启用时先替换再恢复，避免替换期间多执行一次
+	if !cfg.enabled() {
+		if err := s.Pause(cfg.Name); err != nil {
+			return err
+		}
+	}
+	if !old.sameSchedule(cfg) {
+		if err := s.Replace(cfg.Name, opt); err != nil {
+			return err
+		}
+	}
+	if cfg.enabled() {
+		if err := s.Resume(cfg.Name); err != nil {
+			return err
+		}
+	}
+	l.applied[cfg.Name] = cfg
+	return nil
+}
+
+// option 将定义转换为 CronOption，调用方需持有 l.mu。
+func (l *Loader) option(cfg JobConfig) (CronOption, error) {
+	fn, ok := l.funcs[cfg.Func]
+	if !ok || fn == nil {
+		return CronOption{}, fmt.Errorf("%w: %q", ErrFuncNotFound, cfg.Func)
+	}
+	opt := CronOption{Spec: cfg.Spec, Task: fn, Quartz: cfg.Quartz}
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return CronOption{}, fmt.Errorf("m_cron: invalid timezone %q: %w", cfg.Timezone, err)
+		}
+		opt.Location = loc
+	}
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return CronOption{}, fmt.Errorf("m_cron: invalid timeout %q: %w", cfg.Timeout, err)
+		}
+		if d < 0 {
+			return CronOption{}, fmt.Errorf("m_cron: invalid timeout %q: negative", cfg.Timeout)
+		}
+		opt.Timeout = d
+	}
+	return opt, nil
+}
@@ -0,0 +1,196 @@
+package mcron
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-startgo/go-utils/mfile"
+)
+
+// TestLoader 测试从配置文件加载任务与热更新
+func TestLoader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cron.json")
+	s := NewScheduler(SchedulerOption{})
+	defer s.Stop()
+	if err := s.AddJob("manual", "@every 1h", func() {}); err != nil {
+		t.Fatalf("AddJob error = %v", err)
+	}
+
+	l := NewLoader(LoaderOption{Path: path, Scheduler: s})
+	noop := func(ctx context.Context) error { return nil }
+	l.Register("report", noop)
+	l.Register("cleanup", noop)
+
+	// 先写临时文件再重命名，Watch 不会读到写了一半的内容
+	write := func(content string) {
+		t.Helper()
+		tmp := path + ".tmp"
+		if err := mfile.Write(tmp, content); err != nil {
+			t.Fatalf("Write error = %v", err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatalf("Rename error = %v", err)
+		}
+	}
+	specs := func() map[string]JobInfo {
+		res := map[string]JobInfo{}
+		for _, info := range s.List() {
+			res[info.Name] = info
+		}
+		return res
+	}
+
+	write(`{"jobs": [
+		{"name": "report", "spec": "0 0 12 * * *", "timezone": "Asia/Shanghai", "timeout": "5m"},
+		{"name": "clean", "spec": "@every 10m", "func": "cleanup", "enabled": false}
+	]}`)
+	if err := l.Load(); err != nil {
+		t.Fatalf("Load error = %v", err)
+	}
+	jobs := specs()
+	if len(jobs) != 3 || jobs["report"].Paused || !jobs["clean"].Paused {
+		t.Fatalf("List = %+v", jobs)
+	}
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	if next := jobs["report"].Next.In(shanghai); next.Hour() != 12 {
+		t.Errorf("report Next = %v, want 12:00 Asia/Shanghai", next)
+	}
+
+	// 无效定义只报告错误，原任务保持不变；删除的任务被移除；手动添加的任务不受影响
+	write(`{"jobs": [
+		{"name": "report", "spec": "0 0 25 * * *"},
+		{"name": "missing", "spec": "@every 1m"},
+		{"name": "zone", "spec": "@every 1m", "func": "cleanup", "timezone": "Mars/Base"},
+		{"name": "slow", "spec": "@every 1m", "func": "cleanup", "timeout": "-1s"},
+		{"name": "manual", "spec": "@every 1m", "func": "cleanup"}
+	]}`)
+	err := l.Load()
+	if !errors.Is(err, ErrFuncNotFound) || !errors.Is(err, ErrJobExists) {
+		t.Errorf("Load error = %v, want ErrFuncNotFound and ErrJobExists", err)
+	}
+	for _, want := range []string{`"report"`, `"zone"`, `"slow"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Load error = %v, want mention %s", err, want)
+		}
+	}
+	jobs = specs()
+	if len(jobs) != 2 || jobs["report"].Spec != "0 0 12 * * *" || jobs["manual"].Spec != "@every 1h" {
+		t.Errorf("List = %+v", jobs)
+	}
+
+	// 文件整体无法解析时不做任何改动
+	write(`{"jobs": [`)
+	if err := l.Load(); err == nil {
+		t.Error("Load broken json error = nil, want not nil")
+	}
+	if len(s.List()) != 2 {
+		t.Errorf("List = %+v, want unchanged", s.List())
+	}
+
+	// 之前因函数未注册而未生效的任务在 Register 后自动加载
+	l.Register("missing", noop)
+	if info, ok := specs()["missing"]; !ok || info.Spec != "@every 1m" {
+		t.Errorf("Register 后 missing = %+v, %v, want loaded", info, ok)
+	}
+	if err := s.RemoveJob("missing"); err != nil {
+		t.Fatalf("RemoveJob error = %v", err)
+	}
+
+	// Watch 发现文件变化后重新调度
+	errCh := make(chan error, 10)
+	l = NewLoader(LoaderOption{Path: path, Scheduler: s, Interval: 10 * time.Millisecond, OnError: func(err error) { errCh <- err }})
+	l.Register("report", noop)
+	defer l.Stop()
+	write(`{"jobs": [{"name": "watched", "spec": "@every 1m", "func": "report"}]}`)
+	if err := l.Watch(); err != nil {
+		t.Fatalf("Watch error = %v", err)
+	}
+	write(`{"jobs": [{"name": "watched", "spec": "@every 2m", "func": "report", "enabled": false}]}`)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		info := specs()["watched"]
+		if info.Spec == "@every 2m" && info.Paused {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("watched = %+v, want rescheduled and paused", info)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	write(`{"jobs": [{"name": "watched", "spec": "bad", "func": "report"}]}`)
+	select {
+	case err := <-errCh:
+		if !strings.Contains(err.Error(), "invalid spec") {
+			t.Errorf("OnError = %v, want invalid spec", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnError 未被调用")
+	}
+	if info := specs()["watched"]; info.Spec != "@every 2m" {
+		t.Errorf("watched Spec = %q, want unchanged", info.Spec)
+	}
+}
+
+// TestLoaderReload 测试 Watch 轮询时的防抖与解析失败处理
+func TestLoaderReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cron.json")
+	s := NewScheduler(SchedulerOption{})
+	defer s.Stop()
+	l := NewLoader(LoaderOption{Path: path, Scheduler: s})
+	l.Register("report", func(ctx context.Context) error { return nil })
+
+	good := `{"jobs": [{"name": "report", "spec": "@every 1m"}]}`
+	if err := mfile.Write(path, good); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Load(); err != nil {
+		t.Fatalf("Load error = %v", err)
+	}
+
+	// 内容变化后第一次读取只记录，不加载
+	changed := `{"jobs": [{"name": "report", "spec": "@every 2m"}]}`
+	if err := mfile.Write(path, changed); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.reload(); err != nil {
+		t.Fatalf("reload error = %v", err)
+	}
+	if got := s.List()[0].Spec; got != "@every 1m" {
+		t.Fatalf("首次读到变化即加载: Spec = %q", got)
+	}
+	if err := l.reload(); err != nil {
+		t.Fatalf("reload error = %v", err)
+	}
+	if got := s.List()[0].Spec; got != "@every 2m" {
+		t.Fatalf("内容稳定后未加载: Spec = %q", got)
+	}
+
+	// 无法解析的内容报告一次，且不替换最近一次成功加载的内容
+	if err := mfile.Write(path, `{"jobs": [`); err != nil {
+		t.Fatal(err)
+	}
+	_ = l.reload()
+	if err := l.reload(); err == nil {
+		t.Fatal("reload broken json error = nil, want not nil")
+	}
+	if err := l.reload(); err != nil {
+		t.Errorf("同一解析错误重复报告: %v", err)
+	}
+	if !bytes.Equal(l.content, []byte(changed)) {
+		t.Errorf("content = %s, want last good content", l.content)
+	}
+
+	// 恢复为已加载的内容时无需重新加载
+	if err := mfile.Write(path, changed); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.reload(); err != nil || l.changed != nil {
+		t.Errorf("reload = %v, changed = %s, want nil", err, l.changed)
+	}
+}
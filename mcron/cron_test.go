@@ -1,7 +1,13 @@
 package mcron
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -11,10 +17,12 @@ import (
 func TestNew(t *testing.T) {
 	// 测试正常情况
 	t.Run("ValidOption", func(t *testing.T) {
-		var executed int32
+		clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		executed := make(chan struct{}, 1)
 		cr, err := New(CronOption{
-			Func: func() { atomic.StoreInt32(&executed, 1) },
-			Spec: "@every 1s", // 使用相对时间，更可预测
+			Func:  func() { executed <- struct{}{} },
+			Spec:  "@every 1s",
+			Clock: clock,
 		})
 		if err != nil {
 			t.Fatalf("New() error = %v, want nil", err)
@@ -24,12 +32,11 @@ func TestNew(t *testing.T) {
 		}
 		t.Cleanup(func() { cr.Stop() })
 
-		// 等待最多 3 秒，轮询检查是否执行
-		deadline := time.Now().Add(3 * time.Second)
-		for time.Now().Before(deadline) && atomic.LoadInt32(&executed) == 0 {
-			time.Sleep(50 * time.Millisecond)
-		}
-		if atomic.LoadInt32(&executed) == 0 {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		select {
+		case <-executed:
+		case <-time.After(time.Second):
 			t.Error("定时任务未执行")
 		}
 	})
@@ -79,23 +86,756 @@ func TestNew(t *testing.T) {
 
 // TestCronExecution 测试定时任务是否正确执行
 func TestCronExecution(t *testing.T) {
-	var counter int32
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	ticks := make(chan time.Time, 3)
 	cr, err := New(CronOption{
-		Func: func() { atomic.AddInt32(&counter, 1) },
-		Spec: "@every 1s", // 使用相对时间
+		Func:      func() {},
+		Spec:      "@every 1s",
+		Clock:     clock,
+		OnSuccess: func(name string, rec RunRecord) { ticks <- rec.Start },
 	})
 	if err != nil {
 		t.Fatalf("New() error = %v, want nil", err)
 	}
 	t.Cleanup(func() { cr.Stop() })
 
-	// 等待最多 4 秒，任务应该执行至少 3 次
-	deadline := time.Now().Add(4 * time.Second)
-	for time.Now().Before(deadline) && atomic.LoadInt32(&counter) < 3 {
-		time.Sleep(50 * time.Millisecond)
+	// 每前进 1 秒执行一次，共执行 3 次
+	for i := 1; i <= 3; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		select {
+		case got := <-ticks:
+			if want := start.Add(time.Duration(i) * time.Second); !got.Equal(want) {
+				t.Errorf("第 %d 次执行时间 = %v, want %v", i, got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("第 %d 次定时任务未执行", i)
+		}
+	}
+}
+
+// TestScheduler 测试多任务调度器的注册、替换、删除与列表
+func TestScheduler(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := NewScheduler(SchedulerOption{Clock: clock})
+	t.Cleanup(s.Stop)
+
+	a := make(chan struct{}, 1)
+	if err := s.AddJob("b", "@every 1s", func() { t.Error("已删除的任务 b 不应执行") }); err != nil {
+		t.Fatalf("AddJob(b) error = %v", err)
+	}
+	if err := s.AddJob("a", "@every 1s", func() { t.Error("已替换的任务 a 不应执行") }); err != nil {
+		t.Fatalf("AddJob(a) error = %v", err)
+	}
+	if err := s.AddJob("a", "@every 1s", func() {}); !errors.Is(err, ErrJobExists) {
+		t.Errorf("AddJob duplicate error = %v, want %v", err, ErrJobExists)
+	}
+	if err := s.AddJob("", "@every 1s", func() {}); err != ErrEmptyName {
+		t.Errorf("AddJob empty name error = %v, want %v", err, ErrEmptyName)
+	}
+
+	list := s.List()
+	if len(list) != 2 || list[0].Name != "a" || list[1].Name != "b" {
+		t.Fatalf("List() = %+v, want [a b]", list)
+	}
+	if list[0].Next.IsZero() {
+		t.Error("List() Next 不应为零值")
+	}
+
+	// 替换失败时保留原任务
+	if err := s.ReplaceJob("a", "invalid spec", func() {}); err == nil {
+		t.Error("ReplaceJob invalid spec error = nil, want not nil")
+	}
+	if err := s.ReplaceJob("missing", "@every 1s", func() {}); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("ReplaceJob missing error = %v, want %v", err, ErrJobNotFound)
+	}
+	if err := s.ReplaceJob("a", "@every 2s", func() { a <- struct{}{} }); err != nil {
+		t.Fatalf("ReplaceJob error = %v", err)
+	}
+	if got := s.List()[0].Spec; got != "@every 2s" {
+		t.Errorf("ReplaceJob spec = %q, want %q", got, "@every 2s")
+	}
+
+	if err := s.RemoveJob("b"); err != nil {
+		t.Fatalf("RemoveJob error = %v", err)
+	}
+	if err := s.RemoveJob("b"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("RemoveJob twice error = %v, want %v", err, ErrJobNotFound)
+	}
+
+	// 替换后按新的 2s 间隔执行
+	clock.BlockUntil(1)
+	clock.Advance(2 * time.Second)
+	select {
+	case <-a:
+	case <-time.After(time.Second):
+		t.Error("任务 a 未执行")
+	}
+
+	s.Stop()
+	if err := s.AddJob("c", "@every 1s", func() {}); err != ErrStopped {
+		t.Errorf("AddJob after Stop error = %v, want %v", err, ErrStopped)
+	}
+}
+
+// TestReplaceKeepsState 测试替换任务时保留执行状态，且不重复 Immediate
+func TestReplaceKeepsState(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := NewScheduler(SchedulerOption{Clock: clock, Location: time.UTC})
+	defer s.Stop()
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	err := s.Add("job", CronOption{
+		Spec:      "@every 1h",
+		Immediate: true,
+		Overlap:   OverlapSkip,
+		Func: func() {
+			started <- struct{}{}
+			<-release
+		},
+	})
+	if err != nil {
+		t.Fatalf("Add error = %v", err)
+	}
+	<-started
+
+	var replaced int32
+	err = s.Replace("job", CronOption{
+		Spec:      "@every 1h",
+		Immediate: true,
+		Overlap:   OverlapSkip,
+		Func:      func() { atomic.AddInt32(&replaced, 1) },
+	})
+	if err != nil {
+		t.Fatalf("Replace error = %v", err)
+	}
+	// 旧任务仍在执行，新任务的触发按 OverlapSkip 被跳过
+	if err := s.RunNow("job"); err != nil {
+		t.Fatalf("RunNow error = %v", err)
+	}
+	for s.skipped("job") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	for {
+		if h, _ := s.History("job"); len(h) == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.Stop()
+	if n := atomic.LoadInt32(&replaced); n != 0 {
+		t.Errorf("Replace 后新任务执行了 %d 次, want 0（不应重复 Immediate，重叠时应跳过）", n)
+	}
+	if got := s.List()[0].Skipped; got != 1 {
+		t.Errorf("Skipped = %d, want 1", got)
+	}
+}
+
+// TestOverlapPolicy 测试上一次未结束时再次触发的处理策略
+func TestOverlapPolicy(t *testing.T) {
+	cases := []struct {
+		policy      OverlapPolicy
+		wantRuns    int32
+		wantSkipped int64
+	}{
+		{OverlapAllow, 3, 0},
+		{OverlapSkip, 1, 2},
+		{OverlapQueue, 2, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.policy.String(), func(t *testing.T) {
+			var runs int32
+			release := make(chan struct{})
+			started := make(chan struct{}, 3)
+			j := newJob("slow", CronOption{
+				Overlap: c.policy,
+				Func: func() {
+					atomic.AddInt32(&runs, 1)
+					started <- struct{}{}
+					<-release
+				},
+			}, nil)
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() { defer wg.Done(); j.fire(time.Now(), false) }()
+			<-started
+			// 后两次触发要么开始执行（started），要么被跳过或排队后直接返回（decided）
+			decided := make(chan struct{}, 2)
+			for i := 0; i < 2; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					j.fire(time.Now(), false)
+					decided <- struct{}{}
+				}()
+			}
+			for i := 0; i < 2; i++ {
+				select {
+				case <-started:
+				case <-decided:
+				}
+			}
+			close(release)
+			wg.Wait()
+
+			if got := atomic.LoadInt32(&runs); got != c.wantRuns {
+				t.Errorf("runs = %d, want %d", got, c.wantRuns)
+			}
+			if got := j.skipped.Load(); got != c.wantSkipped {
+				t.Errorf("skipped = %d, want %d", got, c.wantSkipped)
+			}
+		})
+	}
+}
+
+// TestJobErrorsAndHistory 测试可返回错误的任务、panic 恢复、执行记录与回调
+func TestJobErrorsAndHistory(t *testing.T) {
+	errBoom := errors.New("boom")
+	var calls int32
+	var failed, succeeded int32
+	j := newJob("task", CronOption{
+		HistorySize: 2,
+		Task: func(ctx context.Context) error {
+			switch atomic.AddInt32(&calls, 1) {
+			case 1:
+				return errBoom
+			case 2:
+				panic("kaboom")
+			}
+			return nil
+		},
+		OnError:   func(name string, rec RunRecord) { atomic.AddInt32(&failed, 1) },
+		OnSuccess: func(name string, rec RunRecord) { atomic.AddInt32(&succeeded, 1) },
+	}, nil)
+
+	j.fire(time.Now(), false)
+	if h := j.runs(); len(h) != 1 || !errors.Is(h[0].Err, errBoom) {
+		t.Fatalf("history = %+v, want one record with errBoom", h)
+	}
+	j.fire(time.Now(), false)
+	h := j.runs()
+	var pe *PanicError
+	if len(h) != 2 || !errors.As(h[1].Err, &pe) || pe.Value != "kaboom" || len(pe.Stack) == 0 {
+		t.Fatalf("history = %+v, want PanicError with stack", h)
+	}
+	j.fire(time.Now(), false)
+	h = j.runs()
+	if len(h) != 2 || h[1].Err != nil {
+		t.Fatalf("history 应保留最近 2 条且最后一次成功, got %+v", h)
+	}
+	if failed != 2 || succeeded != 1 {
+		t.Errorf("OnError = %d, OnSuccess = %d, want 2 and 1", failed, succeeded)
+	}
+
+	// 调度器默认回调
+	got := make(chan string, 1)
+	s := NewScheduler(SchedulerOption{
+		OnError: func(name string, rec RunRecord) { got <- name },
+	})
+	t.Cleanup(s.Stop)
+	err := s.Add("fail", CronOption{
+		Spec:      "@every 1h",
+		Immediate: true,
+		Task:      func(ctx context.Context) error { return errBoom },
+	})
+	if err != nil {
+		t.Fatalf("Add error = %v", err)
+	}
+	select {
+	case name := <-got:
+		if name != "fail" {
+			t.Errorf("OnError name = %q, want %q", name, "fail")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("调度器默认 OnError 未被调用")
+	}
+	if _, err := s.History("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("History missing error = %v, want %v", err, ErrJobNotFound)
+	}
+}
+
+// TestMisfire 测试基于 FileStore 的启动补跑
+func TestMisfire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "cron.json")
+	store := NewFileStore(path)
+	if _, ok, err := store.Load("report"); ok || err != nil {
+		t.Fatalf("Load on missing file = %v, %v, want false, nil", ok, err)
+	}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	last := start.Add(-3*time.Hour - 30*time.Minute)
+	if err := store.Save("report", last); err != nil {
+		t.Fatalf("Save error = %v", err)
+	}
+	// 重新打开文件，确认已持久化
+	got, ok, err := NewFileStore(path).Load("report")
+	if err != nil || !ok || !got.Equal(last) {
+		t.Fatalf("Load = %v, %v, %v, want %v", got, ok, err, last)
+	}
+
+	cases := []struct {
+		policy MisfirePolicy
+		want   int
+	}{
+		{MisfireIgnore, 0},
+		{MisfireFireOnce, 1},
+		{MisfireFireAll, 3},
+	}
+	for _, c := range cases {
+		if err := NewFileStore(path).Save("report", last); err != nil {
+			t.Fatalf("Save error = %v", err)
+		}
+		// 时钟不前进，执行的只有启动时的补跑
+		runs := make(chan struct{}, 10)
+		s := NewScheduler(SchedulerOption{Store: NewFileStore(path), Clock: NewFakeClock(start)})
+		err := s.Add("report", CronOption{
+			Spec:    "@every 1h",
+			Misfire: c.policy,
+			Func:    func() { runs <- struct{}{} },
+		})
+		if err != nil {
+			t.Fatalf("Add error = %v", err)
+		}
+		for i := 0; i < c.want; i++ {
+			select {
+			case <-runs:
+			case <-time.After(2 * time.Second):
+				t.Fatalf("policy %d runs = %d, want %d", c.policy, i, c.want)
+			}
+		}
+		s.Stop()
+		if n := len(runs); n != 0 {
+			t.Errorf("policy %d runs = %d, want %d", c.policy, c.want+n, c.want)
+		}
+	}
+
+	// 成功执行后保存最近一次补跑的触发时间
+	got, _, _ = NewFileStore(path).Load("report")
+	if want := start.Add(-30 * time.Minute); !got.Equal(want) {
+		t.Errorf("成功执行后状态 = %v, want %v", got, want)
+	}
+}
+
+// TestMisfireTicks 测试补跑计算与保存的触发时间
+func TestMisfireTicks(t *testing.T) {
+	sched, err := specParser.Parse("0 0 12 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	last := now.AddDate(-10, 0, 0)
+	// 错过的次数超过上限时 missedRuns 只保留最早的若干次，FireOnce 仍应补跑最近一次
+	if ticks := missedRuns(sched, last, now, maxMisfireRuns); len(ticks) != maxMisfireRuns || ticks[0].Year() != 2014 {
+		t.Errorf("missedRuns len = %d, first = %v", len(ticks), ticks[0])
+	}
+	want := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+	if got := lastMissedRun(sched, last, now); !got.Equal(want) {
+		t.Errorf("lastMissedRun = %v, want %v", got, want)
+	}
+	if got := lastMissedRun(sched, want, now); !got.IsZero() {
+		t.Errorf("lastMissedRun without misses = %v, want zero", got)
+	}
+	every, _ := specParser.Parse("@every 1s")
+	if got := lastMissedRun(every, now.Add(-time.Hour), now); !got.Equal(now.Add(-time.Second)) {
+		t.Errorf("lastMissedRun @every 1s = %v, want %v", got, now.Add(-time.Second))
+	}
+
+	// 保存的是触发时间，手动执行不更新状态
+	store := NewFileStore(filepath.Join(t.TempDir(), "cron.json"))
+	j := newJob("report", CronOption{Func: func() {}, Store: store}, nil)
+	j.fire(want, false)
+	if got, _, _ := store.Load("report"); !got.Equal(want) {
+		t.Errorf("saved = %v, want tick %v", got, want)
+	}
+	j.fire(time.Now(), true)
+	if got, _, _ := store.Load("report"); !got.Equal(want) {
+		t.Errorf("手动执行后 saved = %v, want %v", got, want)
+	}
+}
+
+// TestFileStoreCorrupt 测试状态文件损坏后的恢复与原子写入
+func TestFileStoreCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cron.json")
+	if err := os.WriteFile(path, []byte(`{"report":`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := NewFileStore(path)
+	if _, ok, err := store.Load("report"); ok || err == nil {
+		t.Fatalf("Load on corrupt file = %v, %v, want false and an error", ok, err)
+	}
+	// 只报告一次，之后按空状态继续
+	if _, ok, err := store.Load("report"); ok || err != nil {
+		t.Fatalf("second Load = %v, %v, want false, nil", ok, err)
+	}
+	if _, err := os.Stat(path + ".corrupt"); err != nil {
+		t.Errorf("损坏的文件应被保留为 .corrupt: %v", err)
+	}
+
+	now := time.Now()
+	if err := store.Save("report", now); err != nil {
+		t.Fatalf("Save error = %v", err)
+	}
+	got, ok, err := NewFileStore(path).Load("report")
+	if err != nil || !ok || !got.Equal(now) {
+		t.Fatalf("Load after Save = %v, %v, %v, want %v", got, ok, err, now)
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			t.Errorf("临时文件未清理: %s", e.Name())
+		}
+	}
+
+	// Save 时首次发现损坏：仍写入新状态，并返回一次错误
+	if err := os.WriteFile(path, []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewFileStore(path).Save("report", now); err == nil {
+		t.Error("Save on corrupt file 应返回错误")
+	}
+	if _, ok, err := NewFileStore(path).Load("report"); !ok || err != nil {
+		t.Errorf("Load after recovering Save = %v, %v, want true, nil", ok, err)
+	}
+}
+
+// failStore 是总是保存失败的 StateStore
+type failStore struct{ err error }
+
+func (f failStore) Load(string) (time.Time, bool, error) { return time.Time{}, false, nil }
+func (f failStore) Save(string, time.Time) error         { return f.err }
+
+// TestStoreSaveError 测试保存状态失败时通过 OnError 上报
+func TestStoreSaveError(t *testing.T) {
+	errSave := errors.New("disk full")
+	var failed, succeeded int32
+	j := newJob("report", CronOption{
+		Func:  func() {},
+		Store: failStore{err: errSave},
+		OnError: func(name string, rec RunRecord) {
+			if errors.Is(rec.Err, errSave) {
+				atomic.AddInt32(&failed, 1)
+			}
+		},
+		OnSuccess: func(name string, rec RunRecord) { atomic.AddInt32(&succeeded, 1) },
+	}, nil)
+	j.fire(time.Now(), false)
+	if failed != 1 || succeeded != 1 {
+		t.Errorf("failed = %d, succeeded = %d, want 1, 1", failed, succeeded)
+	}
+}
+
+// TestFileLocker 测试基于文件锁的跨进程租约
+func TestFileLocker(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "locks")
+	a, b := NewFileLocker(dir), NewFileLocker(dir)
+	tick := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	unlock, ok, err := a.TryLock("report/daily", tick)
+	if err != nil || !ok {
+		t.Fatalf("TryLock = %v, %v, want true, nil", ok, err)
+	}
+	if _, ok, err := b.TryLock("report/daily", tick); err != nil || ok {
+		t.Fatalf("持有期间 TryLock = %v, %v, want false, nil", ok, err)
+	}
+	unlock()
+	if _, ok, err := b.TryLock("report/daily", tick); err != nil || ok {
+		t.Fatalf("同一次触发已执行后 TryLock = %v, %v, want false, nil", ok, err)
+	}
+	unlock, ok, err = b.TryLock("report/daily", tick.Add(time.Minute))
+	if err != nil || !ok {
+		t.Fatalf("下一次触发 TryLock = %v, %v, want true, nil", ok, err)
+	}
+	unlock()
+
+	// 两个实例收到同一次触发时只有一个执行
+	var runs int32
+	for _, l := range []Locker{a, b} {
+		j := newJob("shared", CronOption{Locker: l, Func: func() { atomic.AddInt32(&runs, 1) }}, nil)
+		j.fire(tick, false)
+	}
+	if runs != 1 {
+		t.Errorf("runs = %d, want 1", runs)
+	}
+
+	// 未获取到租约的一次计入 lockSkipped
+	j := newJob("counted", CronOption{Locker: a, Func: func() {}}, nil)
+	j.fire(tick, false)
+	newJob("counted", CronOption{Locker: b, Func: func() {}}, j).fire(tick, false)
+	if got := j.lockSkipped.Load(); got != 1 {
+		t.Errorf("lockSkipped = %d, want 1", got)
+	}
+
+	// 手动执行使用独立租约：同一秒内的两次都会执行，也不影响同一时刻的定时触发
+	runs = 0
+	m := newJob("manual", CronOption{Locker: a, Func: func() { atomic.AddInt32(&runs, 1) }}, nil)
+	m.fire(tick.Add(100*time.Millisecond), true)
+	m.fire(tick.Add(200*time.Millisecond), true)
+	m.fire(tick, false)
+	if runs != 3 || m.lockSkipped.Load() != 0 {
+		t.Errorf("runs = %d, lockSkipped = %d, want 3, 0", runs, m.lockSkipped.Load())
+	}
+
+	// 替换后字符相同的任务名使用不同的锁文件，互不影响
+	for _, pair := range [][2]string{{"a/b", "a_b"}, {"x#manual", "x_manual"}, {"Report", "report"}} {
+		if lockFileName(pair[0]) == lockFileName(pair[1]) {
+			t.Errorf("lockFileName(%q) == lockFileName(%q)", pair[0], pair[1])
+		}
+		unlock, ok, err := a.TryLock(pair[0], tick)
+		if err != nil || !ok {
+			t.Fatalf("TryLock(%q) = %v, %v, want true, nil", pair[0], ok, err)
+		}
+		other, ok, err := b.TryLock(pair[1], tick)
+		if err != nil || !ok {
+			t.Fatalf("TryLock(%q) = %v, %v, want true, nil", pair[1], ok, err)
+		}
+		other()
+		unlock()
+	}
+}
+
+// TestPauseResume 测试暂停、恢复与手动触发
+func TestPauseResume(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	s := NewScheduler(SchedulerOption{Clock: clock, Location: time.UTC})
+	defer s.Stop()
+
+	runs := make(chan time.Time, 10)
+	err := s.Add("job", CronOption{
+		Spec:      "0 * * * * *",
+		Func:      func() {},
+		OnSuccess: func(name string, rec RunRecord) { runs <- rec.Start },
+	})
+	if err != nil {
+		t.Fatalf("Add error = %v", err)
+	}
+	expectRun := func(want bool) {
+		t.Helper()
+		select {
+		case <-runs:
+			if !want {
+				t.Fatal("暂停期间仍然执行")
+			}
+		case <-time.After(100 * time.Millisecond):
+			if want {
+				t.Fatal("任务未执行")
+			}
+		}
+	}
+
+	if err := s.Pause("job"); err != nil {
+		t.Fatalf("Pause error = %v", err)
+	}
+	if paused, err := s.IsPaused("job"); err != nil || !paused {
+		t.Fatalf("IsPaused = %v, %v, want true, nil", paused, err)
+	}
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	expectRun(false)
+
+	// 暂停中仍可手动触发，替换后保持暂停
+	if err := s.RunNow("job"); err != nil {
+		t.Fatalf("RunNow error = %v", err)
+	}
+	expectRun(true)
+	if err := s.Replace("job", CronOption{Spec: "30 * * * * *", Func: func() {}, OnSuccess: func(string, RunRecord) { runs <- time.Time{} }}); err != nil {
+		t.Fatalf("Replace error = %v", err)
+	}
+	if !s.List()[0].Paused {
+		t.Error("Replace 后 Paused = false, want true")
+	}
+
+	if err := s.Resume("job"); err != nil {
+		t.Fatalf("Resume error = %v", err)
+	}
+	clock.BlockUntil(1)
+	clock.Advance(30 * time.Second)
+	expectRun(true)
+
+	if err := s.Pause("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Pause missing error = %v, want ErrJobNotFound", err)
+	}
+	if err := s.RunNow("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("RunNow missing error = %v, want ErrJobNotFound", err)
+	}
+
+	// Cron 上的手动触发同样遵循重叠策略
+	release := make(chan struct{})
+	var count int32
+	cr, err := New(CronOption{
+		Spec:    "0 0 0 1 1 *",
+		Overlap: OverlapSkip,
+		Func: func() {
+			atomic.AddInt32(&count, 1)
+			<-release
+		},
+	})
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	cr.Pause()
+	if !cr.IsPaused() {
+		t.Error("IsPaused = false, want true")
+	}
+	cr.Resume()
+	if cr.IsPaused() {
+		t.Error("IsPaused = true, want false")
+	}
+	if err := cr.RunNow(); err != nil {
+		t.Fatalf("RunNow error = %v", err)
+	}
+	for atomic.LoadInt32(&count) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	_ = cr.RunNow()
+	for cr.Skipped() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	cr.Stop()
+	if count != 1 {
+		t.Errorf("count = %d, want 1", count)
+	}
+	if err := cr.RunNow(); !errors.Is(err, ErrStopped) {
+		t.Errorf("Stop 后 RunNow error = %v, want ErrStopped", err)
+	}
+}
+
+// TestTimeout 测试单次执行超时
+func TestTimeout(t *testing.T) {
+	s := NewScheduler(SchedulerOption{})
+	defer s.Stop()
+
+	done := make(chan RunRecord, 1)
+	err := s.Add("slow", CronOption{
+		Spec:    "@every 1h",
+		Timeout: 20 * time.Millisecond,
+		Task: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		OnError: func(name string, rec RunRecord) { done <- rec },
+	})
+	if err != nil {
+		t.Fatalf("Add error = %v", err)
+	}
+	if err := s.RunNow("slow"); err != nil {
+		t.Fatalf("RunNow error = %v", err)
+	}
+	select {
+	case rec := <-done:
+		if !errors.Is(rec.Err, context.DeadlineExceeded) {
+			t.Errorf("Err = %v, want context.DeadlineExceeded", rec.Err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("超时后任务未结束")
+	}
+}
+
+// TestStopContext 测试带期限的停止
+func TestStopContext(t *testing.T) {
+	s := NewScheduler(SchedulerOption{})
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+	err := s.Add("cooperative", CronOption{
+		Spec: "@every 1h",
+		Task: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return ctx.Err()
+		},
+	})
+	if err != nil {
+		t.Fatalf("Add error = %v", err)
+	}
+	if err := s.RunNow("cooperative"); err != nil {
+		t.Fatalf("RunNow error = %v", err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	begin := time.Now()
+	if err := s.StopContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("StopContext error = %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(begin); d > time.Second {
+		t.Errorf("StopContext took %v, want bounded by ctx", d)
+	}
+	select {
+	case err := <-cancelled:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("task ctx err = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("任务的 ctx 未被取消")
+	}
+
+	// 没有正在执行的任务时立即返回 nil，重复调用安全
+	if err := s.StopContext(context.Background()); err != nil {
+		t.Errorf("second StopContext error = %v, want nil", err)
+	}
+}
+
+// TestStopDropsQueued 测试停止后不再开始 OverlapQueue 排队的那次执行
+func TestStopDropsQueued(t *testing.T) {
+	s := NewScheduler(SchedulerOption{})
+	var runs int32
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	err := s.Add("queued", CronOption{
+		Spec:    "@every 1h",
+		Overlap: OverlapQueue,
+		Func: func() {
+			atomic.AddInt32(&runs, 1)
+			started <- struct{}{}
+			<-release
+		},
+	})
+	if err != nil {
+		t.Fatalf("Add error = %v", err)
+	}
+	if err := s.RunNow("queued"); err != nil {
+		t.Fatalf("RunNow error = %v", err)
+	}
+	<-started
+	if err := s.RunNow("queued"); err != nil {
+		t.Fatalf("RunNow error = %v", err)
+	}
+	s.mu.Lock()
+	j := s.jobs["queued"]
+	s.mu.Unlock()
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(time.Millisecond) {
+		j.mu.Lock()
+		pending := j.pending
+		j.mu.Unlock()
+		if pending {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("第二次 RunNow 未进入排队")
+		}
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	<-s.done
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop 未返回")
 	}
-	if atomic.LoadInt32(&counter) < 3 {
-		t.Errorf("定时任务执行次数不足，期望至少 3 次，实际执行 %d 次", counter)
+	if got := atomic.LoadInt32(&runs); got != 1 {
+		t.Errorf("runs = %d, want 1", got)
 	}
 }
 
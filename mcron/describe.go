@@ -0,0 +1,355 @@
+package mcron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 支持的描述语言。
+const (
+	LangZh = "zh"
+	LangEn = "en"
+)
+
+// Preview 返回 spec 在 from 之后的 n 次触发时间，使用与 New 相同的解析器（支持 Quartz 表达式）。
+// 表达式再也不会触发时返回的切片可能少于 n 个。
+//
+//	times, err := mcron.Preview("0 0 12 * * *", time.Now(), 5)
+func Preview(spec string, from time.Time, n int) ([]time.Time, error) {
+	sched, err := specParser.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("m_cron: invalid spec %q: %w", spec, err)
+	}
+	res := make([]time.Time, 0, max(n, 0))
+	t := from
+	for i := 0; i < n; i++ {
+		t = sched.Next(t)
+		if t.IsZero() {
+			break
+		}
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+// Describe 把表达式转换为可读文字，lang 为 LangZh（默认）或 LangEn。
+//
+//	mcron.Describe("0 0 12 * * *", mcron.LangZh) // "每天 12:00:00"
+//	mcron.Describe("0 0 12 * * *", mcron.LangEn) // "every day at 12:00:00"
+func Describe(spec string, lang string) (string, error) {
+	if lang != "" && lang != LangZh && lang != LangEn {
+		return "", fmt.Errorf("m_cron: unsupported lang %q", lang)
+	}
+	if _, err := specParser.Parse(spec); err != nil {
+		return "", fmt.Errorf("m_cron: invalid spec %q: %w", spec, err)
+	}
+	d := describer{en: lang == LangEn, quartz: IsQuartzSpec(spec)}
+
+	tz, body := splitTZ(strings.TrimSpace(spec))
+	text := d.describe(body)
+	if tz != "" {
+		if d.en {
+			text += " (" + tz + ")"
+		} else {
+			text += "（" + tz + "）"
+		}
+	}
+	return text, nil
+}
+
+// descriptors 把 robfig/cron 的预定义表达式展开为等价的 6 字段表达式。
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+var (
+	monthNamesEn = []string{"", "January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"}
+	weekdayNamesEn = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+	weekdayNamesZh = []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+	ordinalsEn     = []string{"", "first", "second", "third", "fourth", "fifth"}
+)
+
+// describer 保存生成描述所需的上下文。
+type describer struct {
+	en     bool
+	quartz bool // 周字段是否按 Quartz 编号（1=周日）
+}
+
+// describe 生成不含时区部分的描述，body 已通过解析校验。
+func (d describer) describe(body string) string {
+	if strings.HasPrefix(body, "@every ") {
+		dur := strings.TrimSpace(strings.TrimPrefix(body, "@every "))
+		if d.en {
+			return "every " + dur
+		}
+		return "每 " + dur
+	}
+	if full, ok := descriptors[body]; ok {
+		body = full
+	}
+
+	f := strings.Fields(strings.ToUpper(body))
+	sec, min, hour, dom, month, dow := f[0], f[1], f[2], f[3], f[4], f[5]
+	date := d.date(dom, month, dow)
+	if len(f) == 7 && f[6] != "*" && f[6] != "?" {
+		if d.en {
+			date += " in " + d.list(f[6], nil)
+		} else {
+			date += "（" + d.list(f[6], nil) + " 年）"
+		}
+	}
+
+	if isNumber(sec) && isNumber(min) && isNumber(hour) {
+		clock := pad2(hour) + ":" + pad2(min) + ":" + pad2(sec)
+		if d.en {
+			return date + " at " + clock
+		}
+		return date + " " + clock
+	}
+
+	everyDay := dom == "*" || dom == "?"
+	everyDay = everyDay && (dow == "*" || dow == "?") && month == "*"
+	var timeText string
+	if isNumber(sec) && isNumber(min) {
+		// 只有小时在变化，例如 @hourly
+		timeText = d.pastHours(hour, min, sec)
+	} else {
+		timeText = d.clockFields(sec, min, hour)
+	}
+	if everyDay {
+		return timeText
+	}
+	if d.en {
+		return timeText + ", " + date
+	}
+	return date + " " + timeText
+}
+
+// date 描述日、月、周字段。
+func (d describer) date(dom, month, dow string) string {
+	anyDom := dom == "*" || dom == "?"
+	anyDow := dow == "*" || dow == "?"
+
+	var scope string // "每月" / "every month" 或具体月份
+	if month == "*" {
+		scope = d.pick("每月", "every month")
+	} else if d.en {
+		scope = d.list(month, monthNamesEn)
+	} else {
+		scope = "每年 " + d.list(month, nil) + " 月"
+	}
+
+	switch {
+	case !anyDom:
+		return d.dayOfMonth(dom, scope)
+	case !anyDow:
+		return d.dayOfWeek(dow, scope, month == "*")
+	case month == "*":
+		return d.pick("每天", "every day")
+	case d.en:
+		return "every day in " + scope
+	default:
+		return scope + "每天"
+	}
+}
+
+// dayOfMonth 描述日字段。
+func (d describer) dayOfMonth(dom, scope string) string {
+	of := scope
+	if d.en {
+		of = " of " + scope
+	}
+	switch {
+	case dom == "L":
+		return d.pick(scope+"最后一天", "on the last day"+of)
+	case dom == "LW" || dom == "WL":
+		return d.pick(scope+"最后一个工作日", "on the last weekday"+of)
+	case strings.HasPrefix(dom, "L-"):
+		n := dom[2:]
+		return d.pick(scope+"倒数第 "+plusOne(n)+" 天", n+" days before the last day"+of)
+	case strings.HasSuffix(dom, "W"):
+		n := strings.TrimSuffix(dom, "W")
+		return d.pick(scope+"离 "+n+" 日最近的工作日", "on the weekday nearest day "+n+of)
+	case strings.HasPrefix(dom, "*/"):
+		n := dom[2:]
+		return d.pick(scope+"每隔 "+n+" 天", "every "+n+" days"+of)
+	}
+	return d.pick(scope+" "+d.list(dom, nil)+" 日", "on day "+d.list(dom, nil)+of)
+}
+
+// dayOfWeek 描述周字段，everyMonth 表示月字段为 '*'。
+func (d describer) dayOfWeek(dow, scope string, everyMonth bool) string {
+	of := ""
+	if !everyMonth {
+		of = d.pick(scope, " in "+scope)
+	}
+	if strings.HasSuffix(dow, "L") && dow != "L" {
+		wd := d.weekday(strings.TrimSuffix(dow, "L"))
+		return d.pick(scope+"最后一个"+wd, "on the last "+wd+" of "+scope)
+	}
+	if i := strings.Index(dow, "#"); i >= 0 {
+		wd := d.weekday(dow[:i])
+		k, _ := strconv.Atoi(dow[i+1:])
+		return d.pick(scope+"第 "+dow[i+1:]+" 个"+wd, "on the "+ordinalsEn[k]+" "+wd+" of "+scope)
+	}
+	if dow == "L" {
+		dow = "7"
+	}
+	if d.en {
+		return "every " + d.list(dow, nil, d.weekday) + of
+	}
+	return of + "每" + d.list(dow, nil, d.weekday)
+}
+
+// clockFields 描述时、分、秒字段，省略被更细粒度字段隐含的部分。
+func (d describer) clockFields(sec, min, hour string) string {
+	type field struct {
+		expr       string
+		zhUnit     string // 单个取值时的单位
+		zhStep     string // 步长时的单位
+		enUnit     string
+		everyZh    string
+		everyEn    string
+		hourFormat bool
+	}
+	fields := []field{
+		{hour, "点", "小时", "hour", "每小时", "every hour", true},
+		{min, "分", "分钟", "minute", "每分钟", "every minute", false},
+		{sec, "秒", "秒", "second", "每秒", "every second", false},
+	}
+
+	var parts []string
+	for i, f := range fields {
+		lowerVarying := false
+		for _, g := range fields[i+1:] {
+			if g.expr == "*" || strings.Contains(g.expr, "/") {
+				lowerVarying = true
+			}
+		}
+		switch {
+		case f.expr == "*" && lowerVarying:
+			continue
+		case i == 2 && f.expr == "0" && !isNumber(min):
+			continue
+		case f.expr == "*":
+			parts = append(parts, d.pick(f.everyZh, f.everyEn))
+		case strings.Contains(f.expr, "/"):
+			parts = append(parts, d.step(f.expr, f.zhUnit, f.zhStep, f.enUnit))
+		case d.en:
+			parts = append(parts, "at "+f.enUnit+" "+d.list(f.expr, nil))
+		case f.hourFormat:
+			parts = append(parts, d.list(f.expr, nil)+" 点")
+		default:
+			parts = append(parts, "第 "+d.list(f.expr, nil)+" "+f.zhUnit)
+		}
+	}
+	if d.en {
+		for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+			parts[i], parts[j] = parts[j], parts[i]
+		}
+		return strings.Join(parts, ", ")
+	}
+	return strings.Join(parts, "")
+}
+
+// pastHours 描述分、秒固定而小时变化的情况。
+func (d describer) pastHours(hour, min, sec string) string {
+	ms := pad2(min) + ":" + pad2(sec)
+	if hour == "*" {
+		return d.pick("每小时的 "+ms, "at "+ms+" past every hour")
+	}
+	if strings.Contains(hour, "/") {
+		return d.pick(d.step(hour, "点", "小时", "hour")+"的 "+ms, "at "+ms+" past "+d.step(hour, "", "", "hour"))
+	}
+	return d.pick(d.list(hour, nil)+" 点的 "+ms, "at "+ms+" past hour "+d.list(hour, nil))
+}
+
+// step 描述 "*/n"、"a/n"、"a-b/n" 形式的字段。
+func (d describer) step(expr, zhUnit, zhStep, enUnit string) string {
+	i := strings.Index(expr, "/")
+	base, n := expr[:i], expr[i+1:]
+	every := d.pick("每 "+n+" "+zhStep, "every "+n+" "+enUnit+"s")
+	switch {
+	case base == "*":
+		return every
+	case strings.Contains(base, "-"):
+		r := strings.SplitN(base, "-", 2)
+		return d.pick("第 "+r[0]+" 至 "+r[1]+" "+zhUnit+"内"+every, every+" from "+enUnit+" "+r[0]+" through "+r[1])
+	}
+	return d.pick("从第 "+base+" "+zhUnit+"起"+every, every+" starting at "+enUnit+" "+base)
+}
+
+// list 描述逗号分隔的取值与区间，names 按下标把数字转换为名称，fn 优先于 names。
+func (d describer) list(expr string, names []string, fn ...func(string) string) string {
+	name := func(v string) string {
+		if len(fn) > 0 {
+			return fn[0](v)
+		}
+		if n, err := strconv.Atoi(v); err == nil && names != nil && n >= 0 && n < len(names) {
+			return names[n]
+		}
+		return v
+	}
+	items := strings.Split(expr, ",")
+	for i, item := range items {
+		if r := strings.SplitN(item, "-", 2); len(r) == 2 {
+			items[i] = d.pick(name(r[0])+"至"+name(r[1]), name(r[0])+" through "+name(r[1]))
+			if !d.en && names == nil && len(fn) == 0 {
+				items[i] = r[0] + " 至 " + r[1]
+			}
+			continue
+		}
+		items[i] = name(item)
+	}
+	return strings.Join(items, d.pick("、", ", "))
+}
+
+// weekday 把周字段的数字或名称转换为星期名称，兼容 robfig 与 Quartz 的编号。
+func (d describer) weekday(v string) string {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		idx := map[string]int{"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6}
+		if i, ok := idx[v]; ok {
+			n = i
+		}
+	} else if d.quartz {
+		n--
+	}
+	n = (n%7 + 7) % 7
+	return d.pick(weekdayNamesZh[n], weekdayNamesEn[n])
+}
+
+// pick 按语言返回对应文字。
+func (d describer) pick(zh, en string) string {
+	if d.en {
+		return en
+	}
+	return zh
+}
+
+// isNumber 判断字段是否为单个数字。
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// pad2 把数字字段补足两位。
+func pad2(s string) string {
+	n, _ := strconv.Atoi(s)
+	return fmt.Sprintf("%02d", n)
+}
+
+// plusOne 把数字字符串加一，用于 "L-n" 转换为 "倒数第 n+1 天"。
+func plusOne(s string) string {
+	n, _ := strconv.Atoi(s)
+	return strconv.Itoa(n + 1)
+}
@@ -0,0 +1,74 @@
+package mcron
+
+import (
+	"testing"
+	"time"
+)
+
+// TestPreview 测试预览表达式接下来的触发时间
+func TestPreview(t *testing.T) {
+	from := time.Date(2024, 1, 30, 13, 0, 0, 0, time.UTC)
+	got, err := Preview("0 0 12 * * *", from, 3)
+	if err != nil {
+		t.Fatalf("Preview error = %v", err)
+	}
+	want := []time.Time{
+		time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 2, 12, 0, 0, 0, time.UTC),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Preview len = %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("Preview[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	// Quartz 表达式与年字段：只剩一次触发
+	got, err = Preview("0 0 12 L * ? 2024", time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC), 5)
+	if err != nil {
+		t.Fatalf("Preview quartz error = %v", err)
+	}
+	if len(got) != 2 || !got[1].Equal(time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Preview quartz = %v", got)
+	}
+
+	if _, err := Preview("invalid", from, 1); err == nil {
+		t.Error("Preview invalid error = nil, want not nil")
+	}
+}
+
+// TestDescribe 测试把表达式转换为中文描述
+func TestDescribe(t *testing.T) {
+	cases := []struct {
+		spec, zh, en string
+	}{
+		{"0 0 12 * * *", "每天 12:00:00", "every day at 12:00:00"},
+		{"@daily", "每天 00:00:00", "every day at 00:00:00"},
+		{"@every 5m", "每 5m", "every 5m"},
+		{"0 */5 * * * *", "每 5 分钟", "every 5 minutes"},
+		{"* * * * * *", "每秒", "every second"},
+		{"0 30 9 * * MON-FRI", "每周一至周五 09:30:00", "every Monday through Friday at 09:30:00"},
+		{"0 0 9 ? * 2#1", "每月第 1 个周一 09:00:00", "on the first Monday of every month at 09:00:00"},
+		{"0 0 12 L * ?", "每月最后一天 12:00:00", "on the last day of every month at 12:00:00"},
+		{"0 0 8 1,15 * *", "每月 1、15 日 08:00:00", "on day 1, 15 of every month at 08:00:00"},
+		{"CRON_TZ=Asia/Shanghai 0 0 8 * * *", "每天 08:00:00（Asia/Shanghai）", "every day at 08:00:00 (Asia/Shanghai)"},
+	}
+	for _, c := range cases {
+		if got, err := Describe(c.spec, LangZh); err != nil || got != c.zh {
+			t.Errorf("Describe(%q, zh) = %q, %v, want %q", c.spec, got, err, c.zh)
+		}
+		if got, err := Describe(c.spec, LangEn); err != nil || got != c.en {
+			t.Errorf("Describe(%q, en) = %q, %v, want %q", c.spec, got, err, c.en)
+		}
+	}
+
+	if _, err := Describe("invalid", LangZh); err == nil {
+		t.Error("Describe invalid spec error = nil, want not nil")
+	}
+	if _, err := Describe("0 0 12 * * *", "fr"); err == nil {
+		t.Error("Describe unsupported lang error = nil, want not nil")
+	}
+}
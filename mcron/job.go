@@ -0,0 +1,292 @@
+package mcron
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// OverlapPolicy 决定上一次执行尚未结束时又到了触发时间该如何处理。
+type OverlapPolicy int
+
+const (
+	// OverlapAllow 允许并发执行（默认，与 robfig/cron 行为一致）。
+	OverlapAllow OverlapPolicy = iota
+	// OverlapSkip 上一次仍在执行时跳过本次触发。
+	OverlapSkip
+	// OverlapQueue 上一次仍在执行时排队一次，结束后立即补跑；
+	// 已有排队时的后续触发会被跳过。
+	OverlapQueue
+)
+
+// String 返回策略名称，便于日志输出。
+func (p OverlapPolicy) String() string {
+	switch p {
+	case OverlapAllow:
+		return "allow"
+	case OverlapSkip:
+		return "skip"
+	case OverlapQueue:
+		return "queue"
+	}
+	return "unknown"
+}
+
+// defaultHistorySize 是未配置 HistorySize 时每个任务保留的执行记录条数。
+const defaultHistorySize = 20
+
+// RunRecord 是任务单次执行的记录。
+type RunRecord struct {
+	Start    time.Time     // 开始时间
+	Duration time.Duration // 耗时
+	Err      error         // 执行错误，panic 会被转换为 *PanicError
+}
+
+// Hook 是任务执行结束后的回调，name 为任务名称。
+type Hook func(name string, rec RunRecord)
+
+// PanicError 是任务 panic 后被恢复得到的错误，包含 panic 值与堆栈。
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+// Error 实现 error 接口。
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("m_cron: panic: %v\n%s", e.Value, e.Stack)
+}
+
+// job 是 Scheduler 内部记录的单个任务。
+type job struct {
+	name  string
+	opt   CronOption
+	sched cron.Schedule
+	ctx   context.Context // 执行 ctx 的父 ctx，为空时使用 context.Background()
+	stop  <-chan struct{} // 调度器停止时关闭，可为空
+	clock Clock           // 为空时使用系统时间
+
+	next   time.Time // 下一次触发时间，由调度循环在持有 Scheduler.mu 时维护
+	prev   time.Time // 上一次触发时间
+	paused bool      // 是否已暂停，同样由 Scheduler.mu 保护
+
+	onError   Hook       // 已合并调度器默认值的失败回调
+	onSuccess Hook       // 已合并调度器默认值的成功回调
+	store     StateStore // 已合并调度器默认值的状态存储，可为空
+	locker    Locker     // 已合并调度器默认值的跨进程锁，可为空
+
+	*jobState
+}
+
+// jobState 是同名任务的执行状态，Replace 时由新旧任务共享，
+// 使替换前仍在执行的那一次继续参与重叠判断，执行记录与跳过次数也得以延续。
+type jobState struct {
+	histMu  sync.Mutex
+	history []RunRecord
+
+	mu          sync.Mutex
+	running     int       // 正在执行的次数
+	pending     bool      // OverlapQueue 下是否有一次排队
+	pendingTick time.Time // 排队那次的触发时间
+	pendingMan  bool      // 排队那次是否为手动执行
+	latest      *job      // 最新的任务定义，排队的那次按它执行
+
+	skipped     atomic.Int64 // 因重叠被跳过的触发次数
+	lockSkipped atomic.Int64 // 因其他实例持有租约而跳过的次数
+}
+
+// manualLeaseSuffix 附加在手动执行（RunNow、Immediate）的租约名称后，
+// 使手动执行与定时触发使用不同的租约，互不影响。
+const manualLeaseSuffix = "#manual"
+
+// newJob 创建任务，prev 不为空时与其共享执行状态，用于 Replace。
+func newJob(name string, opt CronOption, prev *job) *job {
+	j := &job{name: name, opt: opt, onError: opt.OnError, onSuccess: opt.OnSuccess, store: opt.Store, locker: opt.Locker}
+	if prev != nil {
+		j.jobState = prev.jobState
+	} else {
+		j.jobState = &jobState{}
+	}
+	j.mu.Lock()
+	j.latest = j
+	j.mu.Unlock()
+	return j
+}
+
+// fire 按 OverlapPolicy 决定 tick 这次触发是否执行，manual 表示 RunNow 或 Immediate 发起的手动执行。
+func (j *job) fire(tick time.Time, manual bool) {
+	switch j.opt.Overlap {
+	case OverlapSkip:
+		j.mu.Lock()
+		if j.running > 0 {
+			j.mu.Unlock()
+			j.skipped.Add(1)
+			return
+		}
+		j.running++
+		j.mu.Unlock()
+		defer j.done()
+		j.exec(tick, manual)
+
+	case OverlapQueue:
+		j.mu.Lock()
+		if j.running > 0 {
+			if j.pending {
+				j.skipped.Add(1)
+			}
+			j.pending = true
+			j.pendingTick, j.pendingMan = tick, manual
+			j.mu.Unlock()
+			return
+		}
+		j.running++
+		j.mu.Unlock()
+		defer j.done()
+		for cur := j; ; {
+			cur.exec(tick, manual)
+			j.mu.Lock()
+			again := j.pending
+			tick, manual = j.pendingTick, j.pendingMan
+			j.pending = false
+			cur = j.latest
+			j.mu.Unlock()
+			// 调度器停止后丢弃排队的那次，不再开始新的执行
+			if !again || cur.stopping() {
+				return
+			}
+		}
+
+	default:
+		j.mu.Lock()
+		j.running++
+		j.mu.Unlock()
+		defer j.done()
+		j.exec(tick, manual)
+	}
+}
+
+// done 在一次执行结束后更新计数，即使用户函数 panic 也会被调用。
+func (j *job) done() {
+	j.mu.Lock()
+	j.running--
+	if j.running == 0 {
+		// 防御性地清理排队状态，避免残留到下一轮
+		j.pending = false
+	}
+	j.mu.Unlock()
+}
+
+// exec 执行 tick 这次触发，记录结果并调用回调。
+// 配置了 Locker 时先获取该次触发的租约，未获取到说明其他实例负责本次执行，计入 lockSkipped。
+// 手动执行使用单独的租约名称，避免占用或被同一时刻的定时触发挡住。
+func (j *job) exec(tick time.Time, manual bool) {
+	if j.locker != nil {
+		lease := j.name
+		if manual {
+			lease += manualLeaseSuffix
+		}
+		unlock, ok, err := j.locker.TryLock(lease, tick)
+		if err != nil {
+			callHook(j.onError, j.name, RunRecord{Start: j.now(), Err: err})
+			return
+		}
+		if !ok {
+			j.lockSkipped.Add(1)
+			return
+		}
+		defer unlock()
+	}
+
+	rec := RunRecord{Start: j.now()}
+	ctx := j.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if j.opt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.opt.Timeout)
+		defer cancel()
+	}
+	rec.Err = j.call(ctx)
+	rec.Duration = j.now().Sub(rec.Start)
+	j.record(rec)
+
+	if rec.Err != nil {
+		callHook(j.onError, j.name, rec)
+		return
+	}
+	if j.store != nil && !manual {
+		// 保存的是触发时间而不是开始时间，补跑中途退出时剩下的触发在下次启动时仍会补跑。
+		// 保存失败不影响本次结果（最坏情况是下次启动时多补跑一次），只通过 OnError 上报
+		if err := j.store.Save(j.name, tick); err != nil {
+			callHook(j.onError, j.name, RunRecord{Start: j.now(), Err: err})
+		}
+	}
+	callHook(j.onSuccess, j.name, rec)
+}
+
+// stopping 报告调度器是否已开始停止。
+func (j *job) stopping() bool {
+	select {
+	case <-j.stop:
+		return true
+	default:
+	}
+	return j.ctx != nil && j.ctx.Err() != nil
+}
+
+// now 返回任务时间源的当前时间。
+func (j *job) now() time.Time {
+	if j.clock == nil {
+		return time.Now()
+	}
+	return j.clock.Now()
+}
+
+// call 调用 Task 或 Func，并把 panic 转换为 *PanicError。
+func (j *job) call(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	if j.opt.Task != nil {
+		return j.opt.Task(ctx)
+	}
+	j.opt.Func()
+	return nil
+}
+
+// record 追加一条执行记录，超过 HistorySize 时丢弃最旧的记录。
+func (j *job) record(rec RunRecord) {
+	size := j.opt.HistorySize
+	if size <= 0 {
+		size = defaultHistorySize
+	}
+	j.histMu.Lock()
+	defer j.histMu.Unlock()
+	j.history = append(j.history, rec)
+	if n := len(j.history) - size; n > 0 {
+		j.history = append(j.history[:0:0], j.history[n:]...)
+	}
+}
+
+// runs 返回执行记录的副本，按时间先后排列。
+func (j *job) runs() []RunRecord {
+	j.histMu.Lock()
+	defer j.histMu.Unlock()
+	return append([]RunRecord(nil), j.history...)
+}
+
+// callHook 调用回调，回调自身 panic 不会影响调度器。
+func callHook(h Hook, name string, rec RunRecord) {
+	if h == nil {
+		return
+	}
+	defer func() { _ = recover() }()
+	h(name, rec)
+}
@@ -0,0 +1,136 @@
+package mcron
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+/*
+时区与夏令时。
+
+表达式按时区解释的优先级为：CronOption.Location > 表达式的 CRON_TZ= / TZ= 前缀 > 调度器的
+SchedulerOption.Location（默认 time.Local）。CRON_TZ= 前缀在添加任务时即被校验，空的或未知的
+时区名会直接返回错误，而不会像 time.LoadLocation("") 那样悄悄变成 UTC。
+
+本包对日历类表达式（@every 除外）统一采用"墙上时间"语义，即每个被表达式选中的本地时间最多触发一次：
+
+  - 夏令时开始（如 America/New_York 2024-03-10 02:00 跳到 03:00）：落在被跳过区间内的时间
+    顺延同样的长度后触发，02:30 的任务在 03:30 EDT 执行；若顺延后的时刻本身也被选中（如整点任务的
+    03:00），只触发一次。robfig/cron 原生会直接跳过这类触发。
+  - 夏令时结束（如 2024-11-03 02:00 回拨到 01:00）：重复出现的本地时间只在第一次出现时触发，
+    01:30 的任务在 01:30 EDT 执行，01:30 EST 不再执行；整点任务在该小时内也只执行一次。
+    robfig/cron 原生会执行两次。
+*/
+
+// wallParser 在 inner 的解析结果外包装墙上时间语义，并提前校验 CRON_TZ= 前缀。
+type wallParser struct {
+	inner cron.ScheduleParser
+}
+
+// Parse 实现 cron.ScheduleParser。
+func (p wallParser) Parse(spec string) (cron.Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		tz, body := splitTZ(spec)
+		if tz == "" {
+			return nil, fmt.Errorf("m_cron: empty time zone in %q", spec)
+		}
+		if body == "" {
+			return nil, fmt.Errorf("m_cron: missing expression after time zone %q", tz)
+		}
+		if _, err := time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("m_cron: unknown time zone %q: %w", tz, err)
+		}
+	}
+	sched, err := p.inner.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	return wallClock(sched), nil
+}
+
+// wallSchedule 在不含夏令时的 UTC 墙上时间上计算 inner，再映射回 loc 中的真实时刻。
+type wallSchedule struct {
+	inner cron.Schedule  // 时区已被清除，始终以 UTC 计算
+	loc   *time.Location // nil 表示使用传入时间的时区
+}
+
+// wallClock 为日历类表达式包装墙上时间语义，其余类型（如 @every）原样返回。
+func wallClock(sched cron.Schedule) cron.Schedule {
+	switch s := sched.(type) {
+	case *cron.SpecSchedule:
+		inner := *s
+		var loc *time.Location
+		if s.Location != time.Local {
+			loc = s.Location
+		}
+		inner.Location = time.UTC
+		return &wallSchedule{inner: &inner, loc: loc}
+	case *quartzSchedule:
+		inner := *s
+		inner.loc = nil
+		return &wallSchedule{inner: &inner, loc: s.loc}
+	}
+	return sched
+}
+
+// inLocation 把表达式固定到 loc，表达式已通过 CRON_TZ= 指定其他时区时返回错误。
+func inLocation(sched cron.Schedule, loc *time.Location) (cron.Schedule, error) {
+	ws, ok := sched.(*wallSchedule)
+	if !ok {
+		// @every 等按固定间隔执行，与时区无关
+		return sched, nil
+	}
+	if ws.loc != nil && ws.loc.String() != loc.String() {
+		return nil, fmt.Errorf("m_cron: CRON_TZ=%s conflicts with Location %s", ws.loc, loc)
+	}
+	return &wallSchedule{inner: ws.inner, loc: loc}, nil
+}
+
+// Next 实现 cron.Schedule。
+func (w *wallSchedule) Next(t time.Time) time.Time {
+	loc := w.loc
+	if loc == nil {
+		loc = t.Location()
+	}
+	wall := toWall(t.In(loc))
+	for {
+		wall = w.inner.Next(wall)
+		if wall.IsZero() {
+			return time.Time{}
+		}
+		if next := fromWall(wall, loc); next.After(t) {
+			return next.In(t.Location())
+		}
+	}
+}
+
+// toWall 把 t 的本地年月日时分秒原样搬到 UTC，得到不受夏令时影响的墙上时间。
+func toWall(t time.Time) time.Time {
+	y, m, d := t.Date()
+	h, mi, s := t.Clock()
+	return time.Date(y, m, d, h, mi, s, t.Nanosecond(), time.UTC)
+}
+
+// fromWall 把墙上时间映射为 loc 中的真实时刻：重复出现的时间取第一次，
+// 被夏令时跳过的时间按跳变前的偏移量顺延。
+func fromWall(wall time.Time, loc *time.Location) time.Time {
+	// 时区偏移不超过 ±14 小时，跳变前后各取一天以外的偏移量即可覆盖两种可能
+	_, before := wall.Add(-26 * time.Hour).In(loc).Zone()
+	_, after := wall.Add(26 * time.Hour).In(loc).Zone()
+	first := wall.Add(-time.Duration(before) * time.Second)
+	second := wall.Add(-time.Duration(after) * time.Second)
+	if second.Before(first) {
+		first, second = second, first
+	}
+	if toWall(first.In(loc)).Equal(wall) {
+		return first
+	}
+	if toWall(second.In(loc)).Equal(wall) {
+		return second
+	}
+	return wall.Add(-time.Duration(before) * time.Second)
+}
@@ -0,0 +1,88 @@
+package mcron
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLocationDST 测试夏令时切换日的触发时间
+func TestLocationDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("缺少时区数据: %v", err)
+	}
+	at := func(s string) time.Time {
+		tt, err := time.ParseInLocation("2006-01-02 15:04:05 MST", s, ny)
+		if err != nil {
+			t.Fatalf("parse %q: %v", s, err)
+		}
+		return tt
+	}
+	cases := []struct {
+		name string
+		spec string
+		from string
+		want []string
+	}{
+		{"跳过区间顺延", "0 30 2 * * *", "2024-03-09 12:00:00 EST",
+			[]string{"2024-03-10 03:30:00 EDT", "2024-03-11 02:30:00 EDT"}},
+		{"整点任务跳变时只触发一次", "0 0 * * * *", "2024-03-10 00:30:00 EST",
+			[]string{"2024-03-10 01:00:00 EST", "2024-03-10 03:00:00 EDT", "2024-03-10 04:00:00 EDT"}},
+		{"重复时间只触发第一次", "0 30 1 * * *", "2024-11-02 12:00:00 EDT",
+			[]string{"2024-11-03 01:30:00 EDT", "2024-11-04 01:30:00 EST"}},
+		{"整点任务回拨时只触发一次", "0 0 * * * *", "2024-11-03 00:30:00 EDT",
+			[]string{"2024-11-03 01:00:00 EDT", "2024-11-03 02:00:00 EST"}},
+		{"Quartz 表达式同样处理", "0 30 2 ? * * *", "2024-03-09 12:00:00 EST",
+			[]string{"2024-03-10 03:30:00 EDT", "2024-03-11 02:30:00 EDT"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Preview(c.spec, at(c.from), len(c.want))
+			if err != nil {
+				t.Fatalf("Preview error = %v", err)
+			}
+			for i, w := range c.want {
+				if i >= len(got) || !got[i].Equal(at(w)) {
+					t.Fatalf("Preview = %v, want %v", got, c.want)
+				}
+			}
+		})
+	}
+}
+
+// TestLocationOption 测试 Location 选项与 CRON_TZ= 前缀
+func TestLocationOption(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("缺少时区数据: %v", err)
+	}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Location 与 CRON_TZ= 等价
+	a, err := parseOption(CronOption{Func: func() {}, Spec: "0 0 8 * * *", Location: shanghai})
+	if err != nil {
+		t.Fatalf("parseOption error = %v", err)
+	}
+	b, err := parseOption(CronOption{Func: func() {}, Spec: "CRON_TZ=Asia/Shanghai 0 0 8 * * *"})
+	if err != nil {
+		t.Fatalf("parseOption error = %v", err)
+	}
+	want := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	if got := a.Next(from); !got.Equal(want) {
+		t.Errorf("Location Next = %v, want %v", got, want)
+	}
+	if got := b.Next(from); !got.Equal(want) {
+		t.Errorf("CRON_TZ Next = %v, want %v", got, want)
+	}
+
+	for _, opt := range []CronOption{
+		{Func: func() {}, Spec: "CRON_TZ=UTC 0 0 8 * * *", Location: shanghai},
+		{Func: func() {}, Spec: "CRON_TZ= 0 0 8 * * *"},
+		{Func: func() {}, Spec: "CRON_TZ=Mars/Olympus 0 0 8 * * *"},
+		{Func: func() {}, Spec: "TZ=UTC"},
+	} {
+		if _, err := parseOption(opt); err == nil {
+			t.Errorf("parseOption(%q) error = nil, want not nil", opt.Spec)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package mcron
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locker 为任务的每次触发提供跨进程租约，多实例部署时只有拿到租约的实例会执行。
+type Locker interface {
+	// TryLock 尝试获取任务 name 在 tick 这次触发上的租约。
+	// ok 为 false 表示其他实例正持有租约或已经执行过这次触发，本实例应跳过；
+	// ok 为 true 时任务执行结束后需调用 unlock 释放租约。
+	TryLock(name string, tick time.Time) (unlock func(), ok bool, err error)
+}
+
+// errLocked 表示文件锁已被其他进程持有，由各平台的 tryLockFile 返回。
+var errLocked = errors.New("m_cron: lock held by another process")
+
+// FileLocker 是基于文件锁（Unix 上为 flock，Windows 上为 LockFileEx）的 Locker，
+// 适用于同一主机或共享同一支持文件锁的文件系统的多个进程。
+//
+// 每个任务对应 Dir 下的一个锁文件，文件名由任务名中的安全字符加上任务名的哈希组成。
+// 文件内容记录最近一次被执行的触发时间，因此即使各实例的触发时刻略有先后，同一次触发也只会执行一次。
+//
+//	s := mcron.NewScheduler(mcron.SchedulerOption{Locker: mcron.NewFileLocker("/var/run/myapp")})
+type FileLocker struct {
+	Dir string
+}
+
+// NewFileLocker 创建一个在 dir 下存放锁文件的 FileLocker，目录不存在时会自动创建。
+func NewFileLocker(dir string) *FileLocker {
+	return &FileLocker{Dir: dir}
+}
+
+// TryLock 实现 Locker。
+func (l *FileLocker) TryLock(name string, tick time.Time) (func(), bool, error) {
+	if err := os.MkdirAll(l.Dir, 0o755); err != nil {
+		return nil, false, fmt.Errorf("m_cron: lock dir: %w", err)
+	}
+	path := filepath.Join(l.Dir, lockFileName(name))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, false, fmt.Errorf("m_cron: open lock: %w", err)
+	}
+	if err := tryLockFile(f); err != nil {
+		f.Close()
+		if errors.Is(err, errLocked) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("m_cron: lock %s: %w", path, err)
+	}
+	unlock := func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+	}
+
+	// 持有锁后检查这次触发是否已被其他实例执行过
+	b, err := io.ReadAll(io.NewSectionReader(f, 0, 64))
+	if err != nil {
+		unlock()
+		return nil, false, fmt.Errorf("m_cron: read lock: %w", err)
+	}
+	if last, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64); err == nil && last >= tick.UnixNano() {
+		unlock()
+		return nil, false, nil
+	}
+	if err := f.Truncate(0); err != nil {
+		unlock()
+		return nil, false, fmt.Errorf("m_cron: write lock: %w", err)
+	}
+	if _, err := f.WriteAt([]byte(strconv.FormatInt(tick.UnixNano(), 10)), 0); err != nil {
+		unlock()
+		return nil, false, fmt.Errorf("m_cron: write lock: %w", err)
+	}
+	return unlock, true, nil
+}
+
+// maxLockNamePrefix 限制锁文件名中可读部分的长度，避免超出文件系统的文件名长度上限。
+const maxLockNamePrefix = 64
+
+// lockFileName 把任务名转换为安全的文件名。可读部分只用于排查，
+// 不同任务名靠哈希后缀区分，替换或截断字符后也不会撞到同一个文件。
+func lockFileName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+	if len(safe) > maxLockNamePrefix {
+		safe = safe[:maxLockNamePrefix]
+	}
+	sum := sha256.Sum256([]byte(name))
+	return safe + "-" + hex.EncodeToString(sum[:8]) + ".lock"
+}
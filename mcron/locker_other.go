@@ -0,0 +1,18 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package mcron
+
+import (
+	"errors"
+	"os"
+)
+
+// tryLockFile 在不支持文件锁的平台上始终返回错误。
+func tryLockFile(f *os.File) error {
+	return errors.New("m_cron: file lock not supported on this platform")
+}
+
+// unlockFile 在不支持文件锁的平台上什么也不做。
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,17 @@
+package mcron
+
+import (
+	"fmt"
+
+	"github.com/m-startgo/go-utils/mlog"
+)
+
+// LogErrors 返回一个把失败记录写入 mlog 的 Hook，可直接用作 OnError。
+//
+//	myLog := mlog.New(mlog.Config{Path: "./logs", Name: "cron"})
+//	s := mcron.NewScheduler(mcron.SchedulerOption{OnError: mcron.LogErrors(myLog)})
+func LogErrors(l *mlog.Logger) Hook {
+	return func(name string, rec RunRecord) {
+		_ = l.Error(fmt.Sprintf("err:mcron.run|%s|%s|%v", name, rec.Duration, rec.Err))
+	}
+}
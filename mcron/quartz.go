@@ -0,0 +1,499 @@
+package mcron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+/*
+Quartz 表达式兼容层。
+
+字段顺序：秒 分 时 日 月 周 [年]，其中：
+
+  - 日字段支持 '?'、L（当月最后一天）、L-n（倒数第 n+1 天）、nW（离 n 号最近的工作日）、LW（当月最后一个工作日）
+  - 周字段取值 1-7 或 SUN-SAT（1 表示周日），支持 '?'、L（周六）、nL（当月最后一个周 n）、n#k（当月第 k 个周 n）
+  - 年字段可选，取值 1970-2099
+  - 日与周必须有一个为 '?'（若其中一个为 '*' 则视为 '?'）
+
+示例：
+
+	s, err := mcron.ParseQuartz("0 0 12 L * ?")  // 每月最后一天 12:00:00
+	s, err := mcron.ParseQuartz("0 0 9 ? * 2#1") // 每月第一个周一 09:00:00
+
+调度器的解析器会自动识别 Quartz 表达式：7 个字段，或日/周字段中出现 L、W、# 时
+按 Quartz 语义解析（注意此时周字段 1 表示周日）；其余表达式仍按 robfig/cron 语义解析。
+'?' 两种语法都支持但周编号不同，为避免同一个表达式在两种编号间摇摆，
+自动识别时日字段为 '?' 且周字段使用数字的 6 字段表达式（例如 "0 0 9 ? * 1"）会返回错误，
+可改用周名称（"0 0 9 ? * MON"）、把 '?' 换成 '*' 按 robfig 语义解析，
+或设置 CronOption.Quartz、直接使用 ParseQuartz 按 Quartz 语义解析。
+*/
+
+const (
+	quartzMinYear = 1970
+	quartzMaxYear = 2099
+)
+
+var quartzMonthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var quartzDowNames = map[string]int{
+	"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7,
+}
+
+// quartzSchedule 是 Quartz 表达式解析后的 cron.Schedule 实现。
+type quartzSchedule struct {
+	second, minute, hour, month []bool
+	year                        []bool // 下标为 年份-quartzMinYear，nil 表示不限
+	dom                         quartzDom
+	dow                         quartzDow
+	loc                         *time.Location // nil 表示使用传入时间的时区
+}
+
+// quartzDom 描述日字段。
+type quartzDom struct {
+	any         bool   // '?'
+	days        []bool // 普通取值
+	last        bool   // L 或 L-n
+	lastOffset  int    // L-n 中的 n
+	lastWeekday bool   // LW
+	nearest     int    // nW 中的 n
+}
+
+// quartzDow 描述周字段，取值均为 Quartz 编号（1=周日）。
+type quartzDow struct {
+	any    bool   // '?'
+	days   []bool // 普通取值
+	lastOf int    // nL 中的 n
+	nthDay int    // n#k 中的 n
+	nth    int    // n#k 中的 k
+}
+
+// quartzParser 是自动识别 Quartz 表达式的 cron.ScheduleParser。
+type quartzParser struct {
+	fallback cron.ScheduleParser
+}
+
+// Parse 实现 cron.ScheduleParser。
+func (p quartzParser) Parse(spec string) (cron.Schedule, error) {
+	if IsQuartzSpec(spec) {
+		return parseQuartz(spec)
+	}
+	if ambiguousWeekday(spec) {
+		return nil, fmt.Errorf("m_cron: ambiguous day-of-week in %q: '?' with numeric weekday, use names like MON or set Quartz", spec)
+	}
+	return p.fallback.Parse(spec)
+}
+
+// ambiguousWeekday 判断 6 字段表达式是否在日字段为 '?' 的同时用数字表示周，
+// 这类表达式按 robfig 与 Quartz 编号会落在不同的日期上。
+func ambiguousWeekday(spec string) bool {
+	_, body := splitTZ(strings.TrimSpace(spec))
+	fields := strings.Fields(body)
+	if len(fields) != 6 || fields[3] != "?" {
+		return false
+	}
+	return strings.ContainsAny(fields[5], "0123456789")
+}
+
+// IsQuartzSpec 判断表达式是否需要自动按 Quartz 语义解析：7 个字段，或日/周字段使用了 L、W、#。
+// '?' 不作为判断依据，与数字周编号同时出现时解析器会返回错误，见文件开头的说明。
+func IsQuartzSpec(spec string) bool {
+	_, body := splitTZ(strings.TrimSpace(spec))
+	if strings.HasPrefix(body, "@") {
+		return false
+	}
+	fields := strings.Fields(body)
+	if len(fields) == 7 {
+		return true
+	}
+	if len(fields) != 6 {
+		return false
+	}
+	dom := strings.ToUpper(fields[3])
+	dow := strings.ToUpper(fields[5])
+	return strings.ContainsAny(dom, "LW") || strings.ContainsAny(dow, "L#")
+}
+
+// ParseQuartz 按 Quartz 语义解析表达式（支持 CRON_TZ= / TZ= 前缀），
+// 夏令时处理与调度器一致，见 location.go。
+func ParseQuartz(spec string) (cron.Schedule, error) {
+	sched, err := parseQuartz(spec)
+	if err != nil {
+		return nil, err
+	}
+	return wallClock(sched), nil
+}
+
+// parseQuartz 解析 Quartz 表达式，返回未经墙上时间包装的 *quartzSchedule。
+func parseQuartz(spec string) (cron.Schedule, error) {
+	tz, body := splitTZ(strings.TrimSpace(spec))
+	var loc *time.Location
+	if tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("m_cron: quartz: provided bad location %s: %w", tz, err)
+		}
+		loc = l
+	}
+
+	fields := strings.Fields(body)
+	if len(fields) != 6 && len(fields) != 7 {
+		return nil, fmt.Errorf("m_cron: quartz: expected 6 or 7 fields, found %d: %q", len(fields), body)
+	}
+
+	q := &quartzSchedule{loc: loc}
+	var err error
+	if q.second, err = parseQuartzList(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("m_cron: quartz: second: %w", err)
+	}
+	if q.minute, err = parseQuartzList(fields[1], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("m_cron: quartz: minute: %w", err)
+	}
+	if q.hour, err = parseQuartzList(fields[2], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("m_cron: quartz: hour: %w", err)
+	}
+	if q.month, err = parseQuartzList(fields[4], 1, 12, quartzMonthNames); err != nil {
+		return nil, fmt.Errorf("m_cron: quartz: month: %w", err)
+	}
+	if len(fields) == 7 && fields[6] != "*" && fields[6] != "?" {
+		years, err := parseQuartzList(fields[6], quartzMinYear, quartzMaxYear, nil)
+		if err != nil {
+			return nil, fmt.Errorf("m_cron: quartz: year: %w", err)
+		}
+		q.year = years[quartzMinYear:]
+	}
+
+	domExpr, dowExpr := fields[3], fields[5]
+	switch {
+	case domExpr == "?" && dowExpr == "?":
+		return nil, fmt.Errorf("m_cron: quartz: '?' can only be used in one of day-of-month and day-of-week")
+	case domExpr == "*" && dowExpr == "*":
+		dowExpr = "?"
+	case domExpr == "*" && dowExpr != "?":
+		domExpr = "?"
+	case dowExpr == "*" && domExpr != "?":
+		dowExpr = "?"
+	case domExpr != "?" && dowExpr != "?":
+		return nil, fmt.Errorf("m_cron: quartz: specifying both day-of-month and day-of-week is not supported, use '?' in one of them")
+	}
+	if q.dom, err = parseQuartzDom(domExpr); err != nil {
+		return nil, fmt.Errorf("m_cron: quartz: day-of-month: %w", err)
+	}
+	if q.dow, err = parseQuartzDow(dowExpr); err != nil {
+		return nil, fmt.Errorf("m_cron: quartz: day-of-week: %w", err)
+	}
+	return q, nil
+}
+
+// Next 实现 cron.Schedule，返回严格晚于 t 的下一次触发时间，找不到时返回零值。
+func (q *quartzSchedule) Next(t time.Time) time.Time {
+	origLoc := t.Location()
+	loc := q.loc
+	if loc == nil {
+		loc = origLoc
+	}
+	t = t.In(loc)
+	// 从下一整秒开始
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+
+	yearLimit := t.Year() + 5
+	if q.year != nil {
+		yearLimit = quartzMaxYear
+	}
+
+	y, m, d := t.Date()
+	bh, bm, bs := t.Clock()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC) // 只用于按日历日期迭代
+	for day.Year() <= yearLimit {
+		y, m, d = day.Date()
+		if !q.yearMatch(y) {
+			day = time.Date(y+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+			bh, bm, bs = 0, 0, 0
+			continue
+		}
+		if !q.month[int(m)] {
+			day = time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC)
+			bh, bm, bs = 0, 0, 0
+			continue
+		}
+		if q.dayMatch(y, m, d) {
+			for {
+				h, mi, s, ok := q.timeOfDay(bh, bm, bs)
+				if !ok {
+					break
+				}
+				next := time.Date(y, m, d, h, mi, s, 0, loc)
+				if !next.Before(t) {
+					return next.In(origLoc)
+				}
+				// 夏令时回拨时同一墙上时间可能对应更早的时刻，继续向后查找
+				bh, bm, bs = h, mi, s+1
+				if bs == 60 {
+					bm, bs = bm+1, 0
+				}
+				if bm == 60 {
+					bh, bm = bh+1, 0
+				}
+				if bh == 24 {
+					break
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+		bh, bm, bs = 0, 0, 0
+	}
+	return time.Time{}
+}
+
+// yearMatch 判断年份是否满足年字段。
+func (q *quartzSchedule) yearMatch(y int) bool {
+	if q.year == nil {
+		return true
+	}
+	i := y - quartzMinYear
+	return i >= 0 && i < len(q.year) && q.year[i]
+}
+
+// timeOfDay 返回不早于 h:m:s 的第一个满足时、分、秒字段的时刻。
+func (q *quartzSchedule) timeOfDay(bh, bm, bs int) (int, int, int, bool) {
+	for h := bh; h < 24; h++ {
+		if !q.hour[h] {
+			continue
+		}
+		m0 := 0
+		if h == bh {
+			m0 = bm
+		}
+		for mi := m0; mi < 60; mi++ {
+			if !q.minute[mi] {
+				continue
+			}
+			s0 := 0
+			if h == bh && mi == bm {
+				s0 = bs
+			}
+			for s := s0; s < 60; s++ {
+				if q.second[s] {
+					return h, mi, s, true
+				}
+			}
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// dayMatch 判断某个日期是否满足日字段与周字段。
+func (q *quartzSchedule) dayMatch(y int, m time.Month, d int) bool {
+	last := daysIn(y, m)
+	wd := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Weekday()) + 1 // Quartz 编号
+
+	if !q.dom.any {
+		dom := q.dom
+		switch {
+		case dom.lastWeekday:
+			return d == nearestWeekday(y, m, last)
+		case dom.last:
+			return d == last-dom.lastOffset
+		case dom.nearest > 0:
+			return dom.nearest <= last && d == nearestWeekday(y, m, dom.nearest)
+		default:
+			return dom.days[d]
+		}
+	}
+
+	dow := q.dow
+	switch {
+	case dow.lastOf > 0:
+		return wd == dow.lastOf && d+7 > last
+	case dow.nth > 0:
+		return wd == dow.nthDay && (d-1)/7+1 == dow.nth
+	default:
+		return dow.days[wd]
+	}
+}
+
+// nearestWeekday 返回离 y-m-d 最近且不跨月的工作日。
+func nearestWeekday(y int, m time.Month, d int) int {
+	last := daysIn(y, m)
+	switch time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if d == 1 {
+			return d + 2
+		}
+		return d - 1
+	case time.Sunday:
+		if d == last {
+			return d - 2
+		}
+		return d + 1
+	}
+	return d
+}
+
+// daysIn 返回某年某月的天数。
+func daysIn(y int, m time.Month) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// splitTZ 拆分表达式开头的 CRON_TZ= / TZ= 前缀。
+func splitTZ(spec string) (tz, body string) {
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if strings.HasPrefix(spec, prefix) {
+			i := strings.IndexAny(spec, " \t")
+			if i < 0 {
+				return spec[len(prefix):], ""
+			}
+			return spec[len(prefix):i], strings.TrimSpace(spec[i:])
+		}
+	}
+	return "", spec
+}
+
+// parseQuartzDom 解析日字段。
+func parseQuartzDom(expr string) (quartzDom, error) {
+	e := strings.ToUpper(expr)
+	switch {
+	case e == "?":
+		return quartzDom{any: true}, nil
+	case e == "L":
+		return quartzDom{last: true}, nil
+	case e == "LW" || e == "WL":
+		return quartzDom{lastWeekday: true}, nil
+	case strings.HasPrefix(e, "L-"):
+		n, err := strconv.Atoi(e[2:])
+		if err != nil || n < 0 || n > 30 {
+			return quartzDom{}, fmt.Errorf("invalid offset in %q", expr)
+		}
+		return quartzDom{last: true, lastOffset: n}, nil
+	case strings.HasSuffix(e, "W"):
+		n, err := strconv.Atoi(e[:len(e)-1])
+		if err != nil || n < 1 || n > 31 {
+			return quartzDom{}, fmt.Errorf("invalid day in %q", expr)
+		}
+		return quartzDom{nearest: n}, nil
+	}
+	days, err := parseQuartzList(expr, 1, 31, nil)
+	if err != nil {
+		return quartzDom{}, err
+	}
+	return quartzDom{days: days}, nil
+}
+
+// parseQuartzDow 解析周字段。
+func parseQuartzDow(expr string) (quartzDow, error) {
+	e := strings.ToUpper(expr)
+	switch {
+	case e == "?":
+		return quartzDow{any: true}, nil
+	case e == "L":
+		e = "7"
+	case strings.HasSuffix(e, "L"):
+		n, err := parseQuartzValue(e[:len(e)-1], 1, 7, quartzDowNames)
+		if err != nil {
+			return quartzDow{}, err
+		}
+		return quartzDow{lastOf: n}, nil
+	case strings.Contains(e, "#"):
+		parts := strings.SplitN(e, "#", 2)
+		n, err := parseQuartzValue(parts[0], 1, 7, quartzDowNames)
+		if err != nil {
+			return quartzDow{}, err
+		}
+		k, err := strconv.Atoi(parts[1])
+		if err != nil || k < 1 || k > 5 {
+			return quartzDow{}, fmt.Errorf("invalid nth in %q, must be 1-5", expr)
+		}
+		return quartzDow{nthDay: n, nth: k}, nil
+	}
+	days, err := parseQuartzList(e, 1, 7, quartzDowNames)
+	if err != nil {
+		return quartzDow{}, err
+	}
+	return quartzDow{days: days}, nil
+}
+
+// parseQuartzList 解析逗号分隔的取值列表，返回长度为 max+1 的标记切片。
+func parseQuartzList(expr string, min, max int, names map[string]int) ([]bool, error) {
+	set := make([]bool, max+1)
+	for _, item := range strings.Split(strings.ToUpper(expr), ",") {
+		if err := parseQuartzItem(item, min, max, names, set); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// parseQuartzItem 解析单个 '*'、'a'、'a-b'、'a/n'、'a-b/n'、'*/n' 形式的取值。
+func parseQuartzItem(item string, min, max int, names map[string]int, set []bool) error {
+	if item == "" {
+		return fmt.Errorf("empty item")
+	}
+	rangeExpr, step := item, 1
+	if i := strings.Index(item, "/"); i >= 0 {
+		n, err := strconv.Atoi(item[i+1:])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid step in %q", item)
+		}
+		rangeExpr, step = item[:i], n
+	}
+
+	var start, end int
+	switch {
+	case rangeExpr == "*" || rangeExpr == "?":
+		start, end = min, max
+	case strings.Contains(rangeExpr, "-"):
+		parts := strings.SplitN(rangeExpr, "-", 2)
+		var err error
+		if start, err = parseQuartzValue(parts[0], min, max, names); err != nil {
+			return err
+		}
+		if end, err = parseQuartzValue(parts[1], min, max, names); err != nil {
+			return err
+		}
+	default:
+		v, err := parseQuartzValue(rangeExpr, min, max, names)
+		if err != nil {
+			return err
+		}
+		start, end = v, v
+		if step > 1 {
+			// "a/n" 表示从 a 开始每 n 个
+			end = max
+		}
+	}
+
+	// 支持跨越边界的区间，例如 FRI-MON、22-2
+	span := end - start
+	if span < 0 {
+		span += max - min + 1
+	}
+	for i := 0; i <= span; i += step {
+		v := start + i
+		if v > max {
+			v -= max - min + 1
+		}
+		set[v] = true
+	}
+	return nil
+}
+
+// parseQuartzValue 解析单个数值或名称并校验范围。
+func parseQuartzValue(s string, min, max int, names map[string]int) (int, error) {
+	if v, ok := names[s]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, min, max)
+	}
+	return v, nil
+}
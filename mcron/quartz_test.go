@@ -0,0 +1,141 @@
+package mcron
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseQuartz 测试 Quartz 表达式的解析与触发时间
+func TestParseQuartz(t *testing.T) {
+	from := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC) // 周一
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"0 0 12 L * ?", time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)},
+		{"0 0 12 L-2 * ?", time.Date(2024, 1, 29, 12, 0, 0, 0, time.UTC)},
+		{"0 0 12 LW 3 ?", time.Date(2024, 3, 29, 12, 0, 0, 0, time.UTC)},    // 3/31 是周日
+		{"0 0 12 1W 6 ?", time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)},     // 6/1 是周六，不跨月
+		{"0 0 12 15W * ?", time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)},   // 当天即工作日
+		{"0 0 9 ? * 2#1", time.Date(2024, 2, 5, 9, 0, 0, 0, time.UTC)},      // 二月第一个周一
+		{"0 0 9 ? * 6L", time.Date(2024, 1, 26, 9, 0, 0, 0, time.UTC)},      // 一月最后一个周五
+		{"0 0 9 ? * MON-FRI", time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)}, // 下一个工作日
+		{"0 0 9 ? * 1", time.Date(2024, 1, 21, 9, 0, 0, 0, time.UTC)},       // 1 表示周日
+		{"0 0 9 ? * L", time.Date(2024, 1, 20, 9, 0, 0, 0, time.UTC)},       // L 表示周六
+		{"0 0/20 10 * * ?", time.Date(2024, 1, 15, 10, 20, 0, 0, time.UTC)},
+		{"0 0 0 1 1 ? 2026", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 0 29 FEB ? *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=Asia/Shanghai 0 0 8 * * ?", time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		s, err := ParseQuartz(c.spec)
+		if err != nil {
+			t.Fatalf("ParseQuartz(%q) error = %v", c.spec, err)
+		}
+		if got := s.Next(from); !got.Equal(c.want) {
+			t.Errorf("ParseQuartz(%q).Next = %v, want %v", c.spec, got, c.want)
+		}
+	}
+
+	// 超出年字段范围时返回零值
+	s, err := ParseQuartz("0 0 0 1 1 ? 2020")
+	if err != nil {
+		t.Fatalf("ParseQuartz error = %v", err)
+	}
+	if got := s.Next(from); !got.IsZero() {
+		t.Errorf("Next = %v, want zero", got)
+	}
+}
+
+// TestParseQuartzInvalid 测试非法 Quartz 表达式返回错误
+func TestParseQuartzInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"0 0 12 ? * ?",
+		"0 0 12 1 * 2",
+		"0 0 12 32 * ?",
+		"0 0 12 ? * 8",
+		"0 0 12 ? * 2#6",
+		"0 0 12 L-31 * ?",
+		"0 0 12 * * ? 1900",
+		"0 0 12 * ?",
+	} {
+		if _, err := ParseQuartz(spec); err == nil {
+			t.Errorf("ParseQuartz(%q) error = nil, want not nil", spec)
+		}
+	}
+}
+
+// TestIsQuartzSpec 测试 Quartz 表达式的自动识别
+func TestIsQuartzSpec(t *testing.T) {
+	cases := map[string]bool{
+		"0 0 12 * * *":        false,
+		"0 0 12 * * WED":      false,
+		"0 0 12 * JUL *":      false,
+		"@every 1s":           false,
+		"0 0 12 * * ?":        false,
+		"0 0 9 ? * 1":         false,
+		"0 0 12 L * ?":        true,
+		"0 0 12 15W * *":      true,
+		"0 0 9 * * 2#1":       true,
+		"0 0 12 * * * 2030":   true,
+		"TZ=UTC 0 0 12 L * ?": true,
+	}
+	for spec, want := range cases {
+		if got := IsQuartzSpec(spec); got != want {
+			t.Errorf("IsQuartzSpec(%q) = %v, want %v", spec, got, want)
+		}
+	}
+
+	// Quartz 表达式可以直接交给调度器
+	s := NewScheduler(SchedulerOption{})
+	defer s.Stop()
+	if err := s.AddJob("quartz", "0 0 12 L * ?", func() {}); err != nil {
+		t.Fatalf("AddJob quartz error = %v", err)
+	}
+}
+
+// TestQuestionMarkWeekday 测试 '?' 与周字段编号：同一表达式只按一种编号解析
+func TestQuestionMarkWeekday(t *testing.T) {
+	from := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC) // 周一
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		// 周名称在两种语法下含义相同
+		{"0 0 9 ? * MON", time.Date(2024, 1, 22, 9, 0, 0, 0, time.UTC)},
+		// 使用了 # 时整个表达式按 Quartz 编号：2 表示周一
+		{"0 0 9 ? * 2#1", time.Date(2024, 2, 5, 9, 0, 0, 0, time.UTC)},
+		{"0 0 9 * * 1", time.Date(2024, 1, 22, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		got, err := Preview(c.spec, from, 1)
+		if err != nil {
+			t.Fatalf("Preview(%q) error = %v", c.spec, err)
+		}
+		if !got[0].Equal(c.want) {
+			t.Errorf("Preview(%q) = %v (%s), want %v (%s)", c.spec, got[0], got[0].Weekday(), c.want, c.want.Weekday())
+		}
+	}
+
+	// '?' 与数字周编号同时出现时无法判断按哪种编号，返回错误
+	for _, spec := range []string{"0 0 9 ? * 1", "0 0 9 ? * 0", "0 0 9 ? * 1-5", "TZ=UTC 0 0 9 ? * 2"} {
+		if _, err := Preview(spec, from, 1); err == nil {
+			t.Errorf("Preview(%q) error = nil, want ambiguous", spec)
+		}
+		if _, err := Describe(spec, LangZh); err == nil {
+			t.Errorf("Describe(%q) error = nil, want ambiguous", spec)
+		}
+	}
+
+	// 显式要求 Quartz 语义时 1 表示周日
+	sched, err := parseOption(CronOption{Spec: "0 0 9 ? * 1", Quartz: true, Func: func() {}})
+	if err != nil {
+		t.Fatalf("parseOption error = %v", err)
+	}
+	if got := sched.Next(from); got.Weekday() != time.Sunday {
+		t.Errorf("Quartz: true Next = %v (%s), want Sunday", got, got.Weekday())
+	}
+	if _, err := parseOption(CronOption{Spec: "0 0 9 ? * 0", Quartz: true, Func: func() {}}); err == nil {
+		t.Error("Quartz: true 时周字段 0 应返回错误")
+	}
+}
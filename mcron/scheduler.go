@@ -0,0 +1,515 @@
+package mcron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+/*
+Scheduler 在一个底层调度器上托管多个具名任务，避免每个任务各自占用一个调度 goroutine。
+
+示例：
+
+	s := mcron.NewScheduler(mcron.SchedulerOption{})
+	defer s.Stop()
+
+	_ = s.AddJob("report", "0 0 12 * * *", func() { fmt.Println("report") })
+	_ = s.AddJob("clean", "@every 10m", func() { fmt.Println("clean") })
+
+	for _, info := range s.List() {
+		fmt.Println(info.Name, info.Spec, info.Next)
+	}
+*/
+
+var (
+	// ErrEmptyName 表示未提供任务名称。
+	ErrEmptyName = errors.New("m_cron: job name is empty")
+	// ErrJobExists 表示同名任务已存在。
+	ErrJobExists = errors.New("m_cron: job already exists")
+	// ErrJobNotFound 表示任务不存在。
+	ErrJobNotFound = errors.New("m_cron: job not found")
+	// ErrStopped 表示调度器已停止，不能再添加任务。
+	ErrStopped = errors.New("m_cron: scheduler stopped")
+)
+
+// specParser 是本包统一使用的解析器：Quartz 表达式交给 ParseQuartz，
+// 其余按带秒字段的 robfig/cron 语法解析（与 cron.WithSeconds() 等价），
+// 结果统一按墙上时间语义处理夏令时。
+var specParser cron.ScheduleParser = wallParser{
+	inner: quartzParser{
+		fallback: cron.NewParser(
+			cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+		),
+	},
+}
+
+// SchedulerOption 是 NewScheduler 的配置项。
+type SchedulerOption struct {
+	Location *time.Location // 调度器使用的时区，为空时使用 time.Local
+
+	// OnError / OnSuccess 是任务未单独配置回调时使用的默认回调，
+	// 例如 OnError: mcron.LogErrors(myLog)。
+	OnError   Hook
+	OnSuccess Hook
+
+	// Store 记录任务最近一次成功执行的触发时间，配合 CronOption.Misfire 在启动时补跑，
+	// 为空时不持久化，例如 Store: mcron.NewFileStore("./cron-state.json")。
+	Store StateStore
+
+	// Locker 为每次触发获取跨进程租约，保证多实例部署时同一次触发只执行一次，
+	// 例如 Locker: mcron.NewFileLocker("/var/run/myapp")。
+	Locker Locker
+
+	// Clock 是调度器使用的时间源，为空时使用系统时间；测试中可传入 NewFakeClock。
+	Clock Clock
+}
+
+// JobInfo 是 List 返回的任务快照。
+type JobInfo struct {
+	Name string    // 任务名称
+	Spec string    // cron 表达式
+	Next time.Time // 下一次执行时间，零值表示不会再执行
+	Prev time.Time // 上一次执行时间，零值表示尚未执行
+
+	Overlap     OverlapPolicy // 重叠策略
+	Skipped     int64         // 因重叠被跳过的触发次数
+	LockSkipped int64         // 因其他实例持有 Locker 租约而跳过的次数
+	Paused      bool          // 是否已暂停
+}
+
+// Scheduler 用一个调度 goroutine 按名称管理多个任务。
+// 表达式解析沿用 robfig/cron，触发循环由本包实现，以便通过 Clock 注入时间源。
+type Scheduler struct {
+	opt   SchedulerOption
+	loc   *time.Location
+	clock Clock
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	stopped bool
+
+	wg       sync.WaitGroup // 跟踪所有正在进行的执行
+	wake     chan struct{}  // 任务变化时唤醒调度循环
+	done     chan struct{}  // Stop 时关闭
+	loopDone chan struct{}  // 调度循环退出时关闭
+
+	runCtx    context.Context    // 所有执行的 ctx 的父 ctx
+	cancelRun context.CancelFunc // StopContext 超时后取消正在进行的执行
+}
+
+// NewScheduler 创建并启动一个调度器。返回的 Scheduler 需要在适当时机 Stop()。
+func NewScheduler(opt SchedulerOption) *Scheduler {
+	s := &Scheduler{
+		opt:      opt,
+		loc:      opt.Location,
+		clock:    opt.Clock,
+		jobs:     map[string]*job{},
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		loopDone: make(chan struct{}),
+	}
+	if s.loc == nil {
+		s.loc = time.Local
+	}
+	if s.clock == nil {
+		s.clock = realClock{}
+	}
+	s.runCtx, s.cancelRun = context.WithCancel(context.Background())
+	go s.run()
+	return s
+}
+
+// run 是调度循环：执行到期的任务，然后睡到最近的下一次触发或任务变化。
+func (s *Scheduler) run() {
+	defer close(s.loopDone)
+	for {
+		s.mu.Lock()
+		now := s.now()
+		var earliest time.Time
+		for _, j := range s.jobs {
+			if j.next.IsZero() {
+				continue
+			}
+			if !j.next.After(now) {
+				tick := j.next
+				j.next = j.sched.Next(now)
+				if !j.paused {
+					// 暂停期间的触发直接丢弃，恢复后不补跑
+					j.prev = tick
+					s.goRun(j, []time.Time{tick}, false)
+				}
+			}
+			if !j.next.IsZero() && (earliest.IsZero() || j.next.Before(earliest)) {
+				earliest = j.next
+			}
+		}
+		s.mu.Unlock()
+
+		var timer Timer
+		var timerC <-chan time.Time
+		if !earliest.IsZero() {
+			timer = s.clock.NewTimer(earliest.Sub(now))
+			timerC = timer.C()
+		}
+		select {
+		case <-timerC:
+		case <-s.wake:
+		case <-s.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// now 返回调度器时区下的当前时间。
+func (s *Scheduler) now() time.Time {
+	return s.clock.Now().In(s.loc)
+}
+
+// notify 唤醒调度循环重新计算下一次触发。
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// AddJob 以 name 注册一个按 spec 执行 fn 的任务，name 在调度器内必须唯一。
+func (s *Scheduler) AddJob(name, spec string, fn func()) error {
+	return s.Add(name, CronOption{Func: fn, Spec: spec})
+}
+
+// Add 与 AddJob 相同，但接受完整的 CronOption。
+func (s *Scheduler) Add(name string, opt CronOption) error {
+	return s.add(name, opt, false)
+}
+
+// add 注册新任务，paused 为 true 时以暂停状态注册，供 Loader 加载已禁用的任务。
+func (s *Scheduler) add(name string, opt CronOption, paused bool) error {
+	if name == "" {
+		return ErrEmptyName
+	}
+	sched, err := parseOption(opt)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrStopped
+	}
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("%w: %q", ErrJobExists, name)
+	}
+	s.jobs[name] = s.schedule(name, opt, sched, nil, paused)
+	s.notify()
+	return nil
+}
+
+// ReplaceJob 用新的 spec 和 fn 替换已存在的同名任务。
+// 新表达式解析失败时保留原任务不变。
+func (s *Scheduler) ReplaceJob(name, spec string, fn func()) error {
+	return s.Replace(name, CronOption{Func: fn, Spec: spec})
+}
+
+// Replace 与 ReplaceJob 相同，但接受完整的 CronOption。已暂停的任务替换后仍保持暂停。
+// 替换不会触发 Immediate 与补跑；替换前仍在执行的那一次继续参与重叠判断，
+// 执行记录与跳过次数保留，排队中的那次按新的定义执行。
+func (s *Scheduler) Replace(name string, opt CronOption) error {
+	if name == "" {
+		return ErrEmptyName
+	}
+	sched, err := parseOption(opt)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrStopped
+	}
+	old, ok := s.jobs[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrJobNotFound, name)
+	}
+	j := s.schedule(name, opt, sched, old, old.paused)
+	j.prev = old.prev
+	s.jobs[name] = j
+	s.notify()
+	return nil
+}
+
+// RemoveJob 删除指定名称的任务，正在执行中的那一次不会被打断。
+func (s *Scheduler) RemoveJob(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrJobNotFound, name)
+	}
+	delete(s.jobs, name)
+	s.notify()
+	return nil
+}
+
+// List 返回当前全部任务的快照，按名称排序。
+func (s *Scheduler) List() []JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		res = append(res, JobInfo{
+			Name: j.name,
+			Spec: j.opt.Spec,
+			Next: j.next,
+			Prev: j.prev,
+
+			Overlap:     j.opt.Overlap,
+			Skipped:     j.skipped.Load(),
+			LockSkipped: j.lockSkipped.Load(),
+			Paused:      j.paused,
+		})
+	}
+	sort.Slice(res, func(i, k int) bool { return res[i].Name < res[k].Name })
+	return res
+}
+
+// Pause 暂停指定任务，任务保留注册信息，暂停期间到期的触发会被丢弃。
+// 已在执行中的那一次不会被打断，重复调用是安全的。
+func (s *Scheduler) Pause(name string) error {
+	return s.setPaused(name, true)
+}
+
+// Resume 恢复已暂停的任务，从下一次触发时间开始继续执行，暂停期间错过的触发不会补跑。
+func (s *Scheduler) Resume(name string) error {
+	return s.setPaused(name, false)
+}
+
+// IsPaused 返回指定任务是否已暂停。
+func (s *Scheduler) IsPaused(name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return false, fmt.Errorf("%w: %q", ErrJobNotFound, name)
+	}
+	return j.paused, nil
+}
+
+// RunNow 在后台立即触发一次指定任务，不影响原有的调度计划。
+// 与定时触发一样遵循重叠策略、Locker 并恢复 panic；已暂停的任务同样可以手动触发。
+// 手动触发使用独立的 Locker 租约，触发时间精确到纳秒，同一秒内多次触发互不影响。
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrStopped
+	}
+	j, ok := s.jobs[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrJobNotFound, name)
+	}
+	s.goRun(j, []time.Time{s.now()}, true)
+	return nil
+}
+
+// setPaused 修改任务的暂停状态。
+func (s *Scheduler) setPaused(name string, paused bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrJobNotFound, name)
+	}
+	j.paused = paused
+	return nil
+}
+
+// History 返回指定任务最近的执行记录，按时间先后排列。
+func (s *Scheduler) History(name string) ([]RunRecord, error) {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrJobNotFound, name)
+	}
+	return j.runs(), nil
+}
+
+// skipped 返回指定任务因重叠被跳过的次数，任务不存在时返回 0。
+func (s *Scheduler) skipped(name string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j, ok := s.jobs[name]; ok {
+		return j.skipped.Load()
+	}
+	return 0
+}
+
+// lockSkipped 返回指定任务因未获取到 Locker 租约而跳过的次数，任务不存在时返回 0。
+func (s *Scheduler) lockSkipped(name string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j, ok := s.jobs[name]; ok {
+		return j.lockSkipped.Load()
+	}
+	return 0
+}
+
+// Stop 停止调度并等待正在运行的任务完成，重复调用是安全的。
+// 需要限制等待时间时使用 StopContext。
+func (s *Scheduler) Stop() {
+	_ = s.StopContext(context.Background())
+}
+
+/*
+StopContext 停止调度并等待正在运行的任务完成，最多等到 ctx 结束。
+ctx 结束时取消所有执行的 ctx 并立即返回 ctx.Err()，此时仍可能有不响应 ctx 的任务在后台运行。
+重复调用是安全的。
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.StopContext(ctx); err != nil {
+		log.Println("部分任务未在 10s 内结束:", err)
+	}
+*/
+func (s *Scheduler) StopContext(ctx context.Context) error {
+	if s == nil || s.done == nil {
+		return nil
+	}
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.done)
+	}
+	s.mu.Unlock()
+	<-s.loopDone
+
+	finished := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		s.cancelRun()
+		return nil
+	case <-ctx.Done():
+		s.cancelRun()
+		return ctx.Err()
+	}
+}
+
+// schedule 创建任务并计算首次触发时间，调用方需持有 s.mu 并在之后调用 notify。
+// old 不为空表示替换，新任务与之共享执行状态；替换或 paused 为 true 时不执行 Immediate 与补跑。
+func (s *Scheduler) schedule(name string, opt CronOption, sched cron.Schedule, old *job, paused bool) *job {
+	j := newJob(name, opt, old)
+	j.sched, j.ctx, j.stop, j.clock, j.paused = sched, s.runCtx, s.done, s.clock, paused
+	if j.onError == nil {
+		j.onError = s.opt.OnError
+	}
+	if j.onSuccess == nil {
+		j.onSuccess = s.opt.OnSuccess
+	}
+	if j.store == nil {
+		j.store = s.opt.Store
+	}
+	if j.locker == nil {
+		j.locker = s.opt.Locker
+	}
+	now := s.now()
+	j.next = sched.Next(now)
+	if paused || old != nil {
+		return j
+	}
+
+	if opt.Immediate {
+		// 非阻塞立即执行一次，同样遵循重叠策略，panic 由 job 自身恢复
+		s.goRun(j, []time.Time{now}, true)
+	}
+	if ticks := s.misfires(j, sched); len(ticks) > 0 {
+		s.goRun(j, ticks, false)
+	}
+	return j
+}
+
+// goRun 在后台按 ticks 依次执行任务，Stop 会等待其结束，停止后不再开始新的执行。
+// 调用方需持有 s.mu 且调度器未停止，保证 wg.Add 不会与 Stop 中的 wg.Wait 并发。
+func (s *Scheduler) goRun(j *job, ticks []time.Time, manual bool) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for _, tick := range ticks {
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+			j.fire(tick, manual)
+		}
+	}()
+}
+
+// misfires 根据 Misfire 策略和已保存的状态返回启动时需要补跑的触发时间。
+// 读取状态失败时通过 OnError 上报并视为没有记录。
+func (s *Scheduler) misfires(j *job, sched cron.Schedule) []time.Time {
+	if j.store == nil || j.opt.Misfire == MisfireIgnore {
+		return nil
+	}
+	last, ok, err := j.store.Load(j.name)
+	if err != nil {
+		callHook(j.onError, j.name, RunRecord{Start: s.now(), Err: err})
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	if j.opt.Misfire == MisfireFireOnce {
+		// missedRuns 只保留最早的若干次，最近错过的那次需要单独计算
+		if t := lastMissedRun(sched, last, s.now()); !t.IsZero() {
+			return []time.Time{t}
+		}
+		return nil
+	}
+	return missedRuns(sched, last, s.now(), maxMisfireRuns)
+}
+
+// parseOption 校验 CronOption 并解析其中的表达式。
+func parseOption(opt CronOption) (cron.Schedule, error) {
+	if opt.Func == nil && opt.Task == nil {
+		return nil, ErrNilFunc
+	}
+	if opt.Spec == "" {
+		return nil, ErrEmptySpec
+	}
+	var (
+		sched cron.Schedule
+		err   error
+	)
+	if opt.Quartz {
+		sched, err = ParseQuartz(opt.Spec)
+	} else {
+		sched, err = specParser.Parse(opt.Spec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("m_cron: invalid spec %q: %w", opt.Spec, err)
+	}
+	if opt.Location != nil {
+		if sched, err = inLocation(sched, opt.Location); err != nil {
+			return nil, fmt.Errorf("m_cron: invalid spec %q: %w", opt.Spec, err)
+		}
+	}
+	return sched, nil
+}
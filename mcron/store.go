@@ -0,0 +1,170 @@
+package mcron
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/m-startgo/go-utils/mfile"
+	"github.com/m-startgo/go-utils/mjson"
+	"github.com/robfig/cron/v3"
+)
+
+// MisfirePolicy 决定进程重启等原因错过的触发在启动时如何补偿。
+type MisfirePolicy int
+
+const (
+	// MisfireIgnore 忽略错过的触发（默认）。
+	MisfireIgnore MisfirePolicy = iota
+	// MisfireFireOnce 只要错过至少一次，就在启动时按最近错过的那次触发补跑一次。
+	MisfireFireOnce
+	// MisfireFireAll 按错过的次数逐次补跑，最多 maxMisfireRuns 次。
+	MisfireFireAll
+)
+
+// maxMisfireRuns 限制 MisfireFireAll 的补跑次数，避免长时间停机后瞬间堆积大量执行。
+const maxMisfireRuns = 1000
+
+// StateStore 持久化每个任务最近一次成功执行的触发时间，用于启动时计算错过的触发。
+// 手动执行（RunNow、Immediate）不对应某次触发，不会更新状态。
+type StateStore interface {
+	// Load 返回任务最近一次成功执行的触发时间，ok 为 false 表示没有记录。
+	Load(name string) (t time.Time, ok bool, err error)
+	// Save 记录任务最近一次成功执行的触发时间。
+	Save(name string, t time.Time) error
+}
+
+// FileStore 是基于 JSON 文件的 StateStore。写入时先写同目录下的临时文件再重命名，
+// 进程在写入中途退出也不会留下半个文件；文件内容损坏时会被改名为 Path+".corrupt" 并从空状态继续。
+//
+//	store := mcron.NewFileStore("./data/cron-state.json")
+//	s := mcron.NewScheduler(mcron.SchedulerOption{Store: store})
+type FileStore struct {
+	Path string
+
+	mu     sync.Mutex
+	loaded bool
+	state  map[string]time.Time
+}
+
+// NewFileStore 创建一个写入 path 的 FileStore，文件不存在时视为空状态。
+func NewFileStore(path string) *FileStore {
+	return &FileStore{Path: path}
+}
+
+// Load 实现 StateStore。
+func (f *FileStore) Load(name string) (time.Time, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.load(); err != nil {
+		return time.Time{}, false, err
+	}
+	t, ok := f.state[name]
+	return t, ok, nil
+}
+
+// Save 实现 StateStore，每次保存都会整体重写文件。
+// 状态文件损坏时仍会写入新状态，并返回一次损坏错误供调用方上报。
+func (f *FileStore) Save(name string, t time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	loadErr := f.load()
+	if !f.loaded {
+		return loadErr
+	}
+	f.state[name] = t
+	b, err := mjson.Marshal(f.state)
+	if err != nil {
+		return fmt.Errorf("m_cron: marshal state: %w", err)
+	}
+	if err := writeFileAtomic(f.Path, b); err != nil {
+		return fmt.Errorf("m_cron: write state: %w", err)
+	}
+	return loadErr
+}
+
+// load 首次访问时读取文件内容，调用方需持有 f.mu。
+// 内容无法解析时把文件改名为 Path+".corrupt"、以空状态继续，并返回错误；此后不再重复报错。
+func (f *FileStore) load() error {
+	if f.loaded {
+		return nil
+	}
+	state := map[string]time.Time{}
+	b, err := mfile.Read(f.Path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		err = nil
+	case err != nil:
+		return fmt.Errorf("m_cron: read state: %w", err)
+	case len(b) > 0:
+		if perr := mjson.Unmarshal(b, &state); perr != nil {
+			state = map[string]time.Time{}
+			err = fmt.Errorf("m_cron: parse state %s, reset to empty: %w", f.Path, perr)
+			if rerr := os.Rename(f.Path, f.Path+".corrupt"); rerr != nil {
+				err = errors.Join(err, rerr)
+			}
+		}
+	}
+	f.state = state
+	f.loaded = true
+	return err
+}
+
+// writeFileAtomic 先写入同目录下的临时文件，再重命名为 path。
+func writeFileAtomic(path string, b []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// missedRuns 返回 last 之后、now 之前错过的触发时间，最多返回最早的 limit 次。
+func missedRuns(sched cron.Schedule, last, now time.Time, limit int) []time.Time {
+	var ticks []time.Time
+	for t := sched.Next(last); !t.IsZero() && t.Before(now) && len(ticks) < limit; t = sched.Next(t) {
+		ticks = append(ticks, t)
+	}
+	return ticks
+}
+
+// lastMissedRun 返回 last 之后、now 之前最近一次错过的触发时间，没有时返回零值。
+// 从 now 往回成倍扩大查找窗口，只遍历最后一个窗口内的触发，避免长时间停机后从 last 逐次遍历。
+func lastMissedRun(sched cron.Schedule, last, now time.Time) time.Time {
+	gap := now.Sub(last)
+	for w := time.Second; ; w *= 2 {
+		from := now.Add(-w)
+		if w > gap/2 {
+			from = last
+		}
+		var latest time.Time
+		for t := sched.Next(from); !t.IsZero() && t.Before(now); t = sched.Next(t) {
+			latest = t
+		}
+		if !latest.IsZero() || from.Equal(last) {
+			return latest
+		}
+	}
+}
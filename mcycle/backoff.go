@@ -0,0 +1,56 @@
+package mcycle
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// defaultMultiplier 是未配置 Multiplier 时每次连续失败后等待时间的倍数。
+const defaultMultiplier = 2
+
+/*
+Backoff 是任务失败后的退避策略：连续失败时下一次执行至少推迟到逐次拉长的等待时间之后，
+等待时间短于正常间隔时仍按 Mode 计算的时间执行，成功一次后恢复正常调度。
+Initial <= 0 表示不退避（默认）。
+
+	cy := mcycle.New(mcycle.Options{
+		TaskCtx:  poll,
+		Interval: 5 * time.Second,
+		Backoff:  mcycle.Backoff{Initial: time.Second, Max: time.Minute, Multiplier: 2, Jitter: 0.2},
+		OnError:  func(err error) { log.Println(err) },
+	})
+
+以上配置在连续失败时依次等待约 1s、2s、4s …… 最长 1m，每次在 ±20% 内随机浮动。
+*/
+type Backoff struct {
+	Initial    time.Duration // 第一次失败后的等待时间，<=0 时不退避
+	Max        time.Duration // 等待时间上限，<=0 时不限制
+	Multiplier float64       // 每多失败一次等待时间乘以的倍数，0 时为 2，1 表示固定等待，小于 1 时按 1 处理
+	Jitter     float64       // 随机抖动比例，取值 [0, 1]，0.2 表示在 ±20% 内浮动
+}
+
+// enabled 返回是否配置了退避。
+func (b Backoff) enabled() bool {
+	return b.Initial > 0
+}
+
+// delay 返回连续失败 failures 次（>=1）后的等待时间。
+func (b Backoff) delay(failures int) time.Duration {
+	mult := b.Multiplier
+	if mult == 0 {
+		mult = defaultMultiplier
+	}
+	mult = math.Max(mult, 1)
+	d := float64(b.Initial) * math.Pow(mult, float64(failures-1))
+	if b.Max > 0 && d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	if j := math.Min(b.Jitter, 1); j > 0 {
+		d *= 1 + j*(2*rand.Float64()-1)
+	}
+	if d > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
@@ -0,0 +1,129 @@
+package mcycle
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// go test -v -run TestBackoff_Delay
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 5 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := b.delay(i + 1); got != w {
+			t.Errorf("第 %d 次失败期望等待 %v，实际为 %v", i+1, w, got)
+		}
+	}
+
+	b = Backoff{Initial: time.Second, Multiplier: 3, Jitter: 0.2}
+	for i := 0; i < 100; i++ {
+		got := b.delay(2)
+		if got < 2400*time.Millisecond || got > 3600*time.Millisecond {
+			t.Fatalf("抖动后期望在 [2.4s, 3.6s] 内，实际为 %v", got)
+		}
+	}
+	// Multiplier 为 1 时等待时间固定
+	b = Backoff{Initial: time.Second, Multiplier: 1}
+	for i := 1; i <= 3; i++ {
+		if got := b.delay(i); got != time.Second {
+			t.Errorf("固定退避第 %d 次失败期望等待 1s，实际为 %v", i, got)
+		}
+	}
+	if (Backoff{}).enabled() {
+		t.Error("零值 Backoff 不应启用")
+	}
+}
+
+// go test -v -run TestCycle_Backoff
+
+func TestCycle_Backoff(t *testing.T) {
+	errFlaky := errors.New("flaky")
+	var runs, failures, reported int32
+	cy := New(Options{
+		TaskCtx: func(ctx context.Context) error {
+			n := atomic.AddInt32(&runs, 1)
+			if n <= 3 {
+				atomic.AddInt32(&failures, 1)
+				return errFlaky
+			}
+			return nil
+		},
+		Interval: 5 * time.Millisecond,
+		Backoff:  Backoff{Initial: 20 * time.Millisecond, Multiplier: 2},
+		OnError: func(err error) {
+			if errors.Is(err, errFlaky) {
+				atomic.AddInt32(&reported, 1)
+			}
+		},
+	})
+	begin := time.Now()
+	cy.Start()
+	for atomic.LoadInt32(&runs) < 4 {
+		time.Sleep(time.Millisecond)
+	}
+	// 三次失败后依次等待 20ms、40ms、80ms
+	if d := time.Since(begin); d < 140*time.Millisecond {
+		t.Errorf("期望退避至少 140ms，实际为 %v", d)
+	}
+	// 成功后恢复按 Interval 执行
+	time.Sleep(50 * time.Millisecond)
+	cy.End().Wait()
+	if n := atomic.LoadInt32(&runs); n < 8 {
+		t.Errorf("成功后期望恢复正常间隔，实际共执行 %d 次", n)
+	}
+	if r := atomic.LoadInt32(&reported); r != 3 {
+		t.Errorf("期望 OnError 被调用 3 次，实际为 %d", r)
+	}
+}
+
+// go test -v -run TestCycle_BackoffKeepsInterval
+
+func TestCycle_BackoffKeepsInterval(t *testing.T) {
+	var runs int32
+	cy := New(Options{
+		TaskCtx: func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return errors.New("always")
+		},
+		Interval: 50 * time.Millisecond,
+		Backoff:  Backoff{Initial: time.Millisecond, Multiplier: 1},
+	})
+	cy.Start()
+	time.Sleep(120 * time.Millisecond)
+	cy.End().Wait()
+	// 退避时间短于 Interval 时仍按 Interval 执行：约 0ms、50ms、100ms 三次
+	if n := atomic.LoadInt32(&runs); n > 4 {
+		t.Errorf("退避不应短于 Interval，120ms 内实际执行 %d 次", n)
+	}
+}
+
+// go test -v -run TestCycle_TaskCtxCancel
+
+func TestCycle_TaskCtxCancel(t *testing.T) {
+	started := make(chan struct{})
+	cy := New(Options{
+		TaskCtx: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		Interval: time.Hour,
+	})
+	// 首次执行是同步的，Start 会一直阻塞到任务返回
+	go cy.Start()
+	<-started
+	waited := make(chan struct{})
+	go func() {
+		cy.End().Wait()
+		close(waited)
+	}()
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("End 后 TaskCtx 的 ctx 未被取消")
+	}
+}
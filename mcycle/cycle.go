@@ -1,6 +1,7 @@
 package mcycle
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,69 +14,446 @@ time.Sleep(5 * time.Second)
 cy.EditTime(2 * time.Second) // 动态修改为2秒
 */
 
+// defaultInterval 是 Interval 未配置或 <=0 时使用的执行间隔。
+const defaultInterval = time.Second
+
+// Mode 决定两次执行之间的间隔如何计算，例如按整分钟执行：
+//
+//	cy := mcycle.New(mcycle.Options{Task: myFunc, Interval: time.Minute, Mode: mcycle.ModeAligned})
+type Mode int
+
+const (
+	// ModeFixedRate 按固定频率执行（默认）：以 Start 时刻为起点每隔 Interval 触发一次，
+	// 任务耗时超过 Interval 时错过的触发直接跳过，不会堆积补跑。
+	ModeFixedRate Mode = iota
+	// ModeFixedDelay 按固定延迟执行：每次任务结束后再等待 Interval 开始下一次。
+	ModeFixedDelay
+	// ModeAligned 对齐到本地时间的 Interval 整数倍边界执行，例如 Interval 为 time.Minute 时
+	// 在每个整分钟执行；Start 时不会立即执行，任务耗时超过 Interval 时跳过错过的边界。
+	ModeAligned
+)
+
+// String 返回模式名称，便于日志输出。
+func (m Mode) String() string {
+	switch m {
+	case ModeFixedRate:
+		return "fixed-rate"
+	case ModeFixedDelay:
+		return "fixed-delay"
+	case ModeAligned:
+		return "aligned"
+	}
+	return "unknown"
+}
+
+// State 是 Cycle 的运行状态。
+type State int32
+
+const (
+	// StateIdle 尚未启动。
+	StateIdle State = iota
+	// StateRunning 正在运行。
+	StateRunning
+	// StateStopped 已停止，可以再次 Start。
+	StateStopped
+)
+
+// String 返回状态名称，便于日志输出。
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateRunning:
+		return "running"
+	case StateStopped:
+		return "stopped"
+	}
+	return "unknown"
+}
+
+// MarshalText 让 State 在 JSON 中输出为名称，便于健康检查接口展示。
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
 type Cycle struct {
-	Task     func()
-	Interval time.Duration
+	Task      func()
+	TaskCtx   func(ctx context.Context) error
+	Interval  time.Duration
+	Mode      Mode
+	Backoff   Backoff
+	OnError   func(err error)
+	MaxRuns   int
+	Until     time.Time
+	Timeout   time.Duration
+	OnOverrun func(elapsed time.Duration)
 
-	status int32
-	stopCh chan struct{}
-	editCh chan time.Duration
-	once   sync.Once
+	mu      sync.Mutex // 保护以下字段以及 SetInterval 之后的 Interval
+	state   State
+	endIdle bool               // 未启动时调用过 End，下一次 Start 只执行首次 Task
+	cancel  context.CancelFunc // 取消本轮运行，End 时调用
+	done    chan struct{}      // 本轮循环退出时关闭
+	editCh  chan struct{}      // 通知循环重新读取 Interval
+	lastRun time.Time          // 最近一次执行的开始时间
+	lastErr error              // 最近一次执行返回的错误，成功后清空
+
+	paused   atomic.Bool
+	inFlight atomic.Bool  // 是否有一次 Task 正在执行
+	runs     atomic.Int64 // 本轮已执行的次数
 }
 
 type Options struct {
 	Task     func()
-	Interval time.Duration
+	Interval time.Duration // 执行间隔，<=0 时为 1s
+	Mode     Mode          // 调度模式，默认 ModeFixedRate
+
+	// TaskCtx 是可返回错误的任务函数，设置后优先于 Task 执行。
+	// ctx 在 End 或 StartContext 的 ctx 结束时被取消，此时返回的错误不会交给 OnError。
+	TaskCtx func(ctx context.Context) error
+	Backoff Backoff         // 连续失败时的退避策略，默认不退避
+	OnError func(err error) // TaskCtx 返回错误后的回调
+
+	// MaxRuns 每轮（每次 Start 之后）最多执行的次数，<=0 表示不限制。
+	// Until 之后不再执行，零值表示不限制。任一条件满足时循环自动结束，状态变为 StateStopped。
+	MaxRuns int
+	Until   time.Time
+
+	// Timeout 单次执行的超时时间，<=0 表示不限制，超时后传给 TaskCtx 的 ctx 被取消。
+	Timeout time.Duration
+	// OnOverrun 在单次执行耗时超过 Interval 时调用，elapsed 为实际耗时。
+	OnOverrun func(elapsed time.Duration)
 }
 
 func New(opt Options) *Cycle {
 	c := &Cycle{
-		Task:     opt.Task,
-		Interval: opt.Interval,
-		status:   1,
-		stopCh:   make(chan struct{}),
-		editCh:   make(chan time.Duration, 1), // 缓冲防阻塞
+		Task:      opt.Task,
+		TaskCtx:   opt.TaskCtx,
+		Interval:  opt.Interval,
+		Mode:      opt.Mode,
+		Backoff:   opt.Backoff,
+		OnError:   opt.OnError,
+		MaxRuns:   opt.MaxRuns,
+		Until:     opt.Until,
+		Timeout:   opt.Timeout,
+		OnOverrun: opt.OnOverrun,
+		editCh:    make(chan struct{}, 1), // 缓冲防阻塞，只需保留一次通知
 	}
 	if c.Task == nil {
 		c.Task = func() {}
 	}
+	if c.Interval <= 0 {
+		c.Interval = defaultInterval
+	}
 	return c
 }
 
+// End 停止循环并立即返回，正在执行的 Task 不会被打断，需要等待其结束时调用 Wait。
+// 重复调用是安全的。尚未启动时调用，下一次 Start 只会同步执行一次 Task 随即停止。
 func (c *Cycle) End() *Cycle {
-	c.once.Do(func() {
-		if atomic.CompareAndSwapInt32(&c.status, 1, 2) {
-			close(c.stopCh)
-		}
-	})
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch c.state {
+	case StateRunning:
+		c.cancel()
+		c.state = StateStopped
+	case StateIdle:
+		c.endIdle = true
+	}
 	return c
 }
 
+// Start 启动循环，除 ModeAligned 外会先同步执行一次 Task，之后的执行在后台进行。
+// 已在运行时调用无效果；End 之后可以再次 Start，会先等上一轮完全退出再开始，
+// 因此不要在 Task 中先 End 再 Start 同一个 Cycle。
 func (c *Cycle) Start() *Cycle {
-	c.Task()
+	return c.StartContext(context.Background())
+}
+
+// StartContext 与 Start 相同，ctx 结束时循环自动停止，传给 TaskCtx 的 ctx 派生自该 ctx。
+func (c *Cycle) StartContext(ctx context.Context) *Cycle {
+	c.mu.Lock()
+	if c.state == StateRunning {
+		c.mu.Unlock()
+		return c
+	}
+	prev := c.done
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	c.state, c.cancel, c.done = StateRunning, cancel, done
+	endIdle := c.endIdle
+	c.endIdle = false
+	c.mu.Unlock()
+
+	if prev != nil {
+		// 保证同一时刻只有一个循环在执行 Task
+		<-prev
+	}
+	c.runs.Store(0)
+	select {
+	case <-c.editCh: // 丢弃启动前的修改通知，下面读取的 Interval 已是最新值
+	default:
+	}
+	c.mu.Lock()
+	if c.Interval <= 0 {
+		// Interval 是导出字段，New 之后仍可能被改为非正数
+		c.Interval = defaultInterval
+	}
+	interval := c.Interval
+	c.mu.Unlock()
+
+	ready := make(chan struct{})
 	go func() {
-		ticker := time.NewTicker(c.Interval)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-c.stopCh:
+		defer c.exit(done)
+		defer cancel()
+		c.loop(ctx, interval, ready)
+	}()
+	<-ready
+	if endIdle {
+		c.End()
+	}
+	return c
+}
+
+// Wait 阻塞直到当前这一轮循环退出（End 或 ctx 结束后正在执行的 Task 也已返回）。
+// 从未启动时立即返回。
+func (c *Cycle) Wait() {
+	c.mu.Lock()
+	done := c.done
+	c.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+// Pause 暂停执行，循环 goroutine 保持运行，暂停期间到期的执行直接跳过。
+// 未启动时调用同样有效，之后 Start 会以暂停状态开始。
+func (c *Cycle) Pause() *Cycle {
+	c.paused.Store(true)
+	return c
+}
+
+// Resume 恢复执行，从下一个计划时间点开始，暂停期间跳过的执行不会补跑。
+func (c *Cycle) Resume() *Cycle {
+	c.paused.Store(false)
+	return c
+}
+
+// IsPaused 返回是否处于暂停状态。
+func (c *Cycle) IsPaused() bool {
+	return c.paused.Load()
+}
+
+// IsRunning 返回是否有一次 Task 正在执行，End 之后仍在收尾的那一次同样算在内。
+func (c *Cycle) IsRunning() bool {
+	return c.inFlight.Load()
+}
+
+// RunCount 返回本轮（最近一次 Start 之后）已执行的次数。
+func (c *Cycle) RunCount() int64 {
+	return c.runs.Load()
+}
+
+// LastRun 返回最近一次执行的开始时间，从未执行时为零值；重新 Start 不会清空。
+func (c *Cycle) LastRun() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastRun
+}
+
+// LastError 返回最近一次执行返回的错误，最近一次执行成功或从未执行时为 nil。
+func (c *Cycle) LastError() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastErr
+}
+
+// setLastErr 记录最近一次执行的错误。
+func (c *Cycle) setLastErr(err error) {
+	c.mu.Lock()
+	c.lastErr = err
+	c.mu.Unlock()
+}
+
+// State 返回当前运行状态。
+func (c *Cycle) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// exit 在循环退出时调用，ctx 结束导致的退出也会把状态置为 StateStopped。
+func (c *Cycle) exit(done chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done == done && c.state == StateRunning {
+		c.state = StateStopped
+	}
+	close(done)
+}
+
+// loop 按 Mode 计算执行时间并执行任务，直到 ctx 结束。
+// 首次执行结束（或确定不需要首次执行）后关闭 ready，StartContext 据此返回。
+// 任务连续失败且配置了 Backoff 时，下一次执行不早于退避时间之后。
+func (c *Cycle) loop(ctx context.Context, interval time.Duration, ready chan struct{}) {
+	started := false
+	markReady := func() {
+		if !started {
+			started = true
+			close(ready)
+		}
+	}
+	defer markReady()
+	if ctx.Err() != nil {
+		return
+	}
+
+	failures := 0
+	// run 执行一次任务并返回下一次执行时间，next 是本次的计划时间
+	run := func(next time.Time) time.Time {
+		start := time.Now()
+		if c.paused.Load() {
+			// 暂停期间跳过本次执行，按原节拍继续计时
+			return c.next(next, start, interval)
+		}
+		c.runs.Add(1)
+		c.mu.Lock()
+		c.lastRun = start
+		c.mu.Unlock()
+
+		c.inFlight.Store(true)
+		err := c.run(ctx)
+		c.inFlight.Store(false)
+		now := time.Now()
+		if elapsed := now.Sub(start); elapsed > interval && c.OnOverrun != nil {
+			c.OnOverrun(elapsed)
+		}
+		if err == nil || ctx.Err() != nil {
+			// 停止导致的取消不计为失败
+			failures = 0
+			c.setLastErr(nil)
+			return c.next(next, now, interval)
+		}
+		failures++
+		c.setLastErr(err)
+		if c.OnError != nil {
+			c.OnError(err)
+		}
+		res := c.next(next, now, interval)
+		if c.Backoff.enabled() {
+			// 退避只会推迟执行，不会让失败后的重试比正常间隔更频繁
+			if t := now.Add(c.Backoff.delay(failures)); t.After(res) {
+				return t
+			}
+		}
+		return res
+	}
+
+	var next time.Time
+	if c.Mode == ModeAligned {
+		next = nextAligned(time.Now(), interval)
+	} else {
+		if c.finished(time.Now()) {
+			return
+		}
+		next = run(time.Now())
+	}
+	markReady()
+	if c.finished(next) {
+		return
+	}
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.editCh:
+			// 新间隔从修改时刻重新计算，多次修改只取最后一次
+			c.mu.Lock()
+			interval = c.Interval
+			c.mu.Unlock()
+			if c.Mode == ModeAligned {
+				next = nextAligned(time.Now(), interval)
+			} else {
+				next = time.Now().Add(interval)
+			}
+		case <-timer.C:
+			if ctx.Err() != nil {
 				return
-			case d := <-c.editCh:
-				ticker.Stop()
-				ticker = time.NewTicker(d)
-			case <-ticker.C:
-				c.Task()
 			}
+			next = run(next)
 		}
-	}()
-	return c
+		if c.finished(next) {
+			return
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(time.Until(next))
+	}
+}
+
+// finished 返回是否已达到 MaxRuns，或计划在 at 的执行已晚于 Until。
+func (c *Cycle) finished(at time.Time) bool {
+	if c.MaxRuns > 0 && c.runs.Load() >= int64(c.MaxRuns) {
+		return true
+	}
+	return !c.Until.IsZero() && at.After(c.Until)
 }
 
-// SetInterval 动态修改定时器间隔
+// run 执行一次任务，TaskCtx 优先于 Task。
+func (c *Cycle) run(ctx context.Context) error {
+	if c.TaskCtx != nil {
+		if c.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+			defer cancel()
+		}
+		return c.TaskCtx(ctx)
+	}
+	c.Task()
+	return nil
+}
+
+// next 按 Mode 计算计划在 prev 执行、在 now 结束的这次任务之后的下一次执行时间。
+func (c *Cycle) next(prev, now time.Time, interval time.Duration) time.Time {
+	switch c.Mode {
+	case ModeFixedDelay:
+		return now.Add(interval)
+	case ModeAligned:
+		return nextAligned(now, interval)
+	}
+	next := prev.Add(interval)
+	if !next.After(now) {
+		// 跳过任务执行期间错过的触发，保持原有的节拍
+		next = next.Add((now.Sub(next)/interval + 1) * interval)
+	}
+	return next
+}
+
+// SetInterval 动态修改定时器间隔，可在任意 goroutine 中调用，并发修改时以最后一次为准。
+// d <= 0 时忽略。
 func (c *Cycle) SetInterval(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	c.mu.Lock()
 	c.Interval = d
+	c.mu.Unlock()
 	select {
-	case c.editCh <- d:
+	case c.editCh <- struct{}{}:
 	default:
+		// 已有未处理的通知，循环处理时会读取到最新的 Interval
 	}
 }
+
+// nextAligned 返回 now 之后第一个本地时间下 d 的整数倍边界。
+func nextAligned(now time.Time, d time.Duration) time.Time {
+	_, offset := now.Zone()
+	shift := time.Duration(offset) * time.Second
+	return now.Add(shift).Truncate(d).Add(d).Add(-shift)
+}
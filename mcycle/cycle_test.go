@@ -1,6 +1,9 @@
 package mcycle
 
 import (
+	"context"
+	"errors"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -92,3 +95,265 @@ func TestCycle_ImmediateEnd(t *testing.T) {
 		t.Errorf("期望最多执行1次，实际为 %d", val)
 	}
 }
+
+// go test -v -run TestCycle_Restart
+
+func TestCycle_Restart(t *testing.T) {
+	var count, running, overlap int32
+	cy := New(Options{
+		Task: func() {
+			if atomic.AddInt32(&running, 1) > 1 {
+				atomic.StoreInt32(&overlap, 1)
+			}
+			atomic.AddInt32(&count, 1)
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		},
+		Interval: 10 * time.Millisecond,
+	})
+	for i := 0; i < 3; i++ {
+		cy.Start()
+		cy.Start() // 重复 Start 不会启动第二个循环
+		if st := cy.State(); st != StateRunning {
+			t.Errorf("期望状态为 running，实际为 %s", st)
+		}
+		time.Sleep(30 * time.Millisecond)
+		cy.End()
+		cy.End()
+		if st := cy.State(); st != StateStopped {
+			t.Errorf("期望状态为 stopped，实际为 %s", st)
+		}
+	}
+	cy.Wait()
+	if atomic.LoadInt32(&overlap) != 0 {
+		t.Error("同一时刻有多个循环在执行 Task")
+	}
+	if val := atomic.LoadInt32(&count); val < 3 {
+		t.Errorf("期望重启后继续执行，实际共执行 %d 次", val)
+	}
+}
+
+// go test -v -run TestCycle_StartContext
+
+func TestCycle_StartContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cy := New(Options{Task: func() {}, Interval: 10 * time.Millisecond})
+	cy.StartContext(ctx)
+	cancel()
+
+	waited := make(chan struct{})
+	go func() {
+		cy.Wait()
+		close(waited)
+	}()
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("ctx 结束后 Wait 未返回")
+	}
+	if st := cy.State(); st != StateStopped {
+		t.Errorf("期望状态为 stopped，实际为 %s", st)
+	}
+}
+
+// go test -v -run TestCycle_Mode
+
+func TestCycle_Mode(t *testing.T) {
+	// 任务耗时 30ms、间隔 20ms：固定频率跳过错过的触发，固定延迟在结束后再等 20ms
+	run := func(mode Mode) int32 {
+		var count int32
+		cy := New(Options{
+			Task: func() {
+				atomic.AddInt32(&count, 1)
+				time.Sleep(30 * time.Millisecond)
+			},
+			Interval: 20 * time.Millisecond,
+			Mode:     mode,
+		})
+		start := time.Now()
+		cy.Start() // 首次执行是同步的，从调用前开始计时
+		time.Sleep(190*time.Millisecond - time.Since(start))
+		cy.End()
+		return atomic.LoadInt32(&count)
+	}
+	if n := run(ModeFixedRate); n < 4 || n > 6 {
+		t.Errorf("固定频率期望执行约5次，实际为 %d", n)
+	}
+	if n := run(ModeFixedDelay); n < 3 || n > 5 {
+		t.Errorf("固定延迟期望执行约4次，实际为 %d", n)
+	}
+}
+
+// go test -v -run TestCycle_Aligned
+
+func TestCycle_Aligned(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 42, 0, time.FixedZone("CST", 8*3600))
+	if got, want := nextAligned(now, time.Minute), now.Add(18*time.Second); !got.Equal(want) {
+		t.Errorf("nextAligned = %v, want %v", got, want)
+	}
+	if got, want := nextAligned(now, 24*time.Hour), time.Date(2024, 1, 2, 0, 0, 0, 0, now.Location()); !got.Equal(want) {
+		t.Errorf("nextAligned day = %v, want %v", got, want)
+	}
+
+	ticks := make(chan time.Time, 10)
+	cy := New(Options{
+		Task:     func() { ticks <- time.Now() },
+		Interval: 50 * time.Millisecond,
+		Mode:     ModeAligned,
+	})
+	cy.Start()
+	defer cy.End()
+	select {
+	case tick := <-ticks:
+		if off := tick.Sub(tick.Truncate(50 * time.Millisecond)); off > 20*time.Millisecond {
+			t.Errorf("执行时间 %v 偏离边界 %v", tick, off)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("对齐模式未执行")
+	}
+}
+
+// go test -v -run TestCycle_Limits
+
+func TestCycle_Limits(t *testing.T) {
+	cy := New(Options{Task: func() {}, Interval: 5 * time.Millisecond, MaxRuns: 3})
+	cy.Start().Wait()
+	if n := cy.RunCount(); n != 3 {
+		t.Errorf("期望执行 3 次，实际为 %d", n)
+	}
+	if st := cy.State(); st != StateStopped {
+		t.Errorf("期望状态为 stopped，实际为 %s", st)
+	}
+	if cy.LastRun().IsZero() {
+		t.Error("LastRun 不应为零值")
+	}
+
+	// 重新 Start 后重新计数
+	cy.Start().Wait()
+	if n := cy.RunCount(); n != 3 {
+		t.Errorf("重启后期望执行 3 次，实际为 %d", n)
+	}
+
+	begin := time.Now()
+	cy = New(Options{Task: func() {}, Interval: 10 * time.Millisecond, Until: begin.Add(55 * time.Millisecond)})
+	cy.Start().Wait()
+	if d := time.Since(begin); d > 200*time.Millisecond {
+		t.Errorf("期望在 Until 后结束，实际耗时 %v", d)
+	}
+	if n := cy.RunCount(); n < 4 || n > 6 {
+		t.Errorf("期望执行约 6 次，实际为 %d", n)
+	}
+}
+
+// go test -v -run TestCycle_PauseResume
+
+func TestCycle_PauseResume(t *testing.T) {
+	var count int32
+	cy := New(Options{
+		Task:     func() { atomic.AddInt32(&count, 1) },
+		Interval: 5 * time.Millisecond,
+	})
+	cy.Pause().Start()
+	time.Sleep(30 * time.Millisecond)
+	if n := atomic.LoadInt32(&count); n != 0 {
+		t.Errorf("暂停期间不应执行，实际为 %d", n)
+	}
+	if !cy.IsPaused() || cy.State() != StateRunning {
+		t.Errorf("期望暂停且循环仍在运行，实际 paused=%v state=%s", cy.IsPaused(), cy.State())
+	}
+	cy.Resume()
+	time.Sleep(30 * time.Millisecond)
+	cy.End().Wait()
+	if n := atomic.LoadInt32(&count); n < 2 {
+		t.Errorf("恢复后期望继续执行，实际为 %d", n)
+	}
+	if n := cy.RunCount(); n != int64(atomic.LoadInt32(&count)) {
+		t.Errorf("RunCount = %d，期望与执行次数 %d 一致", n, count)
+	}
+}
+
+// go test -v -run TestCycle_SetIntervalLastWins
+
+func TestCycle_SetIntervalLastWins(t *testing.T) {
+	var count int32
+	cy := New(Options{
+		Task:     func() { atomic.AddInt32(&count, 1) },
+		Interval: time.Hour,
+	})
+	cy.Start()
+	defer cy.End()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cy.SetInterval(time.Hour)
+		}()
+	}
+	wg.Wait()
+	cy.SetInterval(time.Hour)
+	cy.SetInterval(5 * time.Millisecond) // 通知已满时也不会丢失最后一次修改
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&count); n < 3 {
+		t.Errorf("期望按最后一次设置的间隔执行，实际为 %d", n)
+	}
+}
+
+// go test -v -run TestCycle_TimeoutAndOverrun
+
+func TestCycle_TimeoutAndOverrun(t *testing.T) {
+	errs := make(chan error, 10)
+	overruns := make(chan time.Duration, 10)
+	cy := New(Options{
+		TaskCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		Interval:  10 * time.Millisecond,
+		Timeout:   30 * time.Millisecond,
+		OnError:   func(err error) { errs <- err },
+		OnOverrun: func(elapsed time.Duration) { overruns <- elapsed },
+		MaxRuns:   1,
+	})
+	cy.Start().Wait()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("期望超时错误，实际为 %v", err)
+		}
+	default:
+		t.Error("超时后 OnError 未被调用")
+	}
+	select {
+	case d := <-overruns:
+		if d < 30*time.Millisecond {
+			t.Errorf("OnOverrun 耗时 %v，期望不小于超时时间", d)
+		}
+	default:
+		t.Error("OnOverrun 未被调用")
+	}
+}
+
+// go test -v -run TestCycle_DefaultInterval
+
+func TestCycle_DefaultInterval(t *testing.T) {
+	for _, mode := range []Mode{ModeFixedRate, ModeFixedDelay, ModeAligned} {
+		var count int32
+		cy := New(Options{Task: func() { atomic.AddInt32(&count, 1) }, Mode: mode})
+		if cy.Interval != defaultInterval {
+			t.Errorf("%s: Interval = %s, want %s", mode, cy.Interval, defaultInterval)
+		}
+		// New 之后直接修改导出字段为非正数，Start 时同样使用默认值，不会除零或空转
+		cy.Interval = -time.Second
+		cy.Start()
+		time.Sleep(20 * time.Millisecond)
+		cy.End().Wait()
+		if cy.Interval != defaultInterval {
+			t.Errorf("%s: Start 后 Interval = %s, want %s", mode, cy.Interval, defaultInterval)
+		}
+		if val := atomic.LoadInt32(&count); val > 1 {
+			t.Errorf("%s: 期望最多执行1次，实际为 %d", mode, val)
+		}
+	}
+}
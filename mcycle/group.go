@@ -0,0 +1,193 @@
+package mcycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+Group 按名称管理多个 Cycle，统一启动、停止并在限定时间内等待正在执行的任务结束。
+
+	g := mcycle.NewGroup()
+	_ = g.Add("poll", mcycle.New(mcycle.Options{TaskCtx: poll, Interval: 2 * time.Second}))
+	_ = g.Add("flush", mcycle.New(mcycle.Options{Task: flush, Interval: time.Minute}))
+	g.Start()
+
+	<-sigterm
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := g.Shutdown(ctx); err != nil {
+		log.Println("部分任务未在 10s 内结束:", err)
+	}
+
+Status 返回的快照可直接用于健康检查接口。
+*/
+
+var (
+	// ErrEmptyName 表示未提供名称。
+	ErrEmptyName = errors.New("m_cycle: name is empty")
+	// ErrNilCycle 表示注册的 Cycle 为空。
+	ErrNilCycle = errors.New("m_cycle: cycle is nil")
+	// ErrExists 表示同名 Cycle 已注册。
+	ErrExists = errors.New("m_cycle: cycle already exists")
+	// ErrNotFound 表示 Cycle 不存在。
+	ErrNotFound = errors.New("m_cycle: cycle not found")
+)
+
+// Status 是 Group.Status 返回的单个 Cycle 的快照。
+type Status struct {
+	Name      string        `json:"name"`
+	Interval  time.Duration `json:"interval"`
+	State     State         `json:"state"`
+	Running   bool          `json:"running"` // 是否有一次 Task 正在执行，与 State 无关
+	Paused    bool          `json:"paused"`
+	RunCount  int64         `json:"run_count"`
+	LastRun   time.Time     `json:"last_run"`   // 最近一次执行的开始时间，零值表示从未执行
+	LastError string        `json:"last_error"` // 最近一次执行的错误，为空表示成功或从未执行
+}
+
+// Group 是一组具名 Cycle，零值不可用，请使用 NewGroup 创建。
+type Group struct {
+	mu     sync.Mutex
+	cycles map[string]*Cycle
+}
+
+// NewGroup 创建一个空的 Group。
+func NewGroup() *Group {
+	return &Group{cycles: map[string]*Cycle{}}
+}
+
+// Add 注册一个 Cycle，不会自动启动；Group 已 Start 时需要自行调用 c.Start()。
+func (g *Group) Add(name string, c *Cycle) error {
+	if name == "" {
+		return ErrEmptyName
+	}
+	if c == nil {
+		return ErrNilCycle
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.cycles[name]; ok {
+		return fmt.Errorf("%w: %q", ErrExists, name)
+	}
+	g.cycles[name] = c
+	return nil
+}
+
+// Remove 取消注册并停止指定的 Cycle，不等待其正在执行的任务。
+func (g *Group) Remove(name string) error {
+	g.mu.Lock()
+	c, ok := g.cycles[name]
+	delete(g.cycles, name)
+	g.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	c.End()
+	return nil
+}
+
+// Get 返回指定名称的 Cycle，不存在时返回 nil。
+func (g *Group) Get(name string) *Cycle {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.cycles[name]
+}
+
+// Start 并发启动全部 Cycle，等各自的首次执行（见 Cycle.Start）结束后返回，已在运行的不受影响。
+func (g *Group) Start() {
+	g.StartContext(context.Background())
+}
+
+// StartContext 与 Start 相同，ctx 结束时全部 Cycle 自动停止。
+func (g *Group) StartContext(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, c := range g.list() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.StartContext(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// End 停止全部 Cycle 并立即返回，等待任务结束请使用 Wait 或 Shutdown。
+func (g *Group) End() {
+	for _, c := range g.list() {
+		c.End()
+	}
+}
+
+// Wait 等待全部 Cycle 的循环退出，最多等到 ctx 结束，超时返回 ctx.Err()。
+func (g *Group) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		for _, c := range g.list() {
+			c.Wait()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown 停止全部 Cycle 并等待正在执行的任务结束，最多等到 ctx 结束。
+// End 会取消传给 TaskCtx 的 ctx，响应 ctx 的任务会尽快返回。
+func (g *Group) Shutdown(ctx context.Context) error {
+	g.End()
+	return g.Wait(ctx)
+}
+
+// Status 返回全部 Cycle 的状态快照，按名称排序。
+func (g *Group) Status() []Status {
+	g.mu.Lock()
+	names := make([]string, 0, len(g.cycles))
+	for name := range g.cycles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	cycles := make([]*Cycle, len(names))
+	for i, name := range names {
+		cycles[i] = g.cycles[name]
+	}
+	g.mu.Unlock()
+
+	res := make([]Status, len(names))
+	for i, c := range cycles {
+		c.mu.Lock()
+		res[i] = Status{
+			Name:     names[i],
+			Interval: c.Interval,
+			State:    c.state,
+			LastRun:  c.lastRun,
+		}
+		if c.lastErr != nil {
+			res[i].LastError = c.lastErr.Error()
+		}
+		c.mu.Unlock()
+		res[i].Running = c.IsRunning()
+		res[i].Paused = c.IsPaused()
+		res[i].RunCount = c.RunCount()
+	}
+	return res
+}
+
+// list 返回当前注册的全部 Cycle。
+func (g *Group) list() []*Cycle {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	res := make([]*Cycle, 0, len(g.cycles))
+	for _, c := range g.cycles {
+		res = append(res, c)
+	}
+	return res
+}
@@ -0,0 +1,92 @@
+package mcycle
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m-startgo/go-utils/mjson"
+)
+
+// go test -v -run TestGroup
+
+func TestGroup(t *testing.T) {
+	g := NewGroup()
+	release := make(chan struct{})
+	fast := New(Options{
+		TaskCtx:  func(ctx context.Context) error { return errors.New("upstream down") },
+		Interval: 5 * time.Millisecond,
+	})
+	// slow 的首次执行立即返回（Start 会同步等待它），之后的执行阻塞到 release
+	var slowRuns int32
+	slow := New(Options{
+		Task: func() {
+			if atomic.AddInt32(&slowRuns, 1) > 1 {
+				<-release
+			}
+		},
+		Interval: 5 * time.Millisecond,
+	})
+	if err := g.Add("fast", fast); err != nil {
+		t.Fatalf("Add error = %v", err)
+	}
+	if err := g.Add("slow", slow); err != nil {
+		t.Fatalf("Add error = %v", err)
+	}
+	if err := g.Add("fast", fast); !errors.Is(err, ErrExists) {
+		t.Errorf("重复 Add error = %v, want ErrExists", err)
+	}
+	if err := g.Add("", fast); !errors.Is(err, ErrEmptyName) {
+		t.Errorf("空名称 Add error = %v, want ErrEmptyName", err)
+	}
+	if g.Get("fast") != fast {
+		t.Error("Get 返回的 Cycle 不一致")
+	}
+
+	g.Start()
+	time.Sleep(20 * time.Millisecond)
+
+	st := g.Status()
+	if len(st) != 2 || st[0].Name != "fast" || st[1].Name != "slow" {
+		t.Fatalf("Status = %+v", st)
+	}
+	if st[0].State != StateRunning || st[0].LastError != "upstream down" || st[0].LastRun.IsZero() || st[0].Interval != 5*time.Millisecond {
+		t.Errorf("fast Status = %+v", st[0])
+	}
+	if !st[1].Running {
+		t.Errorf("slow 的任务正在执行，Status = %+v", st[1])
+	}
+	if b, err := mjson.Marshal(st[0]); err != nil || !strings.Contains(string(b), `"state":"running"`) {
+		t.Errorf("Status JSON = %s, %v", b, err)
+	}
+
+	// slow 的任务不结束时 Shutdown 在期限内返回
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := g.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown error = %v, want context.DeadlineExceeded", err)
+	}
+	// 已停止但任务仍在收尾
+	if s := g.Status()[1]; s.State != StateStopped || !s.Running {
+		t.Errorf("slow Status = %+v, want stopped and running", s)
+	}
+	close(release)
+	if err := g.Wait(context.Background()); err != nil {
+		t.Errorf("Wait error = %v", err)
+	}
+	for _, s := range g.Status() {
+		if s.Running || s.State != StateStopped {
+			t.Errorf("%s Status = %+v, want stopped", s.Name, s)
+		}
+	}
+
+	if err := g.Remove("fast"); err != nil {
+		t.Errorf("Remove error = %v", err)
+	}
+	if err := g.Remove("fast"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("重复 Remove error = %v, want ErrNotFound", err)
+	}
+}
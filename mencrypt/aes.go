@@ -0,0 +1,231 @@
+package mencrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+/*
+AES-256-GCM 对称加密，适合把密钥、令牌等敏感配置加密后存入配置文件或数据库字段。
+
+密文是自描述的二进制格式，第一个字节为版本号：
+
+	v1: 0x01 | nonce(12) | 密文+tag —— Encrypt，使用 32 字节密钥
+	v2: 0x02 | salt(16) | 迭代次数(4, 大端) | nonce(12) | 密文+tag —— EncryptWithPassphrase
+
+每次加密都使用随机 nonce，同一明文多次加密得到的密文不同。
+
+示例：
+
+	key, _ := mencrypt.NewKey()
+	s, _ := mencrypt.EncryptBase64(key, []byte("db-password"))
+	plain, _ := mencrypt.DecryptBase64(key, s)
+
+	// 使用口令，salt 与迭代次数保存在密文中
+	c, _ := mencrypt.EncryptWithPassphrase("my passphrase", []byte("secret"))
+	plain, _ = mencrypt.DecryptWithPassphrase("my passphrase", c)
+*/
+
+const (
+	// KeySize 是 AES-256 密钥的字节数。
+	KeySize = 32
+	// SaltSize 是 DeriveKey 推荐的 salt 字节数，也是 EncryptWithPassphrase 使用的长度。
+	SaltSize = 16
+	// KDFIterations 是口令派生密钥时 PBKDF2-SHA256 的迭代次数。
+	KDFIterations = 600000
+)
+
+const (
+	versionKey        byte = 0x01 // 直接使用密钥
+	versionPassphrase byte = 0x02 // 使用口令派生密钥
+	nonceSize              = 12
+	tagSize                = 16
+)
+
+// NewKey 生成一个随机的 AES-256 密钥。
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("err:mencrypt.NewKey|rand|%w", err)
+	}
+	return key, nil
+}
+
+// NewSalt 生成一个 SaltSize 字节的随机 salt。
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("err:mencrypt.NewSalt|rand|%w", err)
+	}
+	return salt, nil
+}
+
+// DeriveKey 使用 PBKDF2-SHA256（KDFIterations 次迭代）从口令派生 AES-256 密钥。
+// 同一口令与 salt 总是得到同一密钥，salt 需要与密文一同保存。
+func DeriveKey(passphrase string, salt []byte) ([]byte, error) {
+	key, err := deriveKey(passphrase, salt, KDFIterations)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.DeriveKey|derive|%w", err)
+	}
+	return key, nil
+}
+
+// Encrypt 使用 AES-256-GCM 加密 plaintext，key 必须为 32 字节。
+func Encrypt(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.Encrypt|key|%w", err)
+	}
+	out := make([]byte, 1+nonceSize, 1+nonceSize+len(plaintext)+tagSize)
+	out[0] = versionKey
+	if _, err := rand.Read(out[1:]); err != nil {
+		return nil, fmt.Errorf("err:mencrypt.Encrypt|nonce|%w", err)
+	}
+	return gcm.Seal(out, out[1:], plaintext, out[:1]), nil
+}
+
+// Decrypt 解密 Encrypt 生成的密文，密钥错误或密文被篡改时返回错误。
+func Decrypt(key, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) == 0 {
+		return nil, fmt.Errorf("err:mencrypt.Decrypt|format|ciphertext is empty")
+	}
+	if ciphertext[0] != versionKey {
+		return nil, fmt.Errorf("err:mencrypt.Decrypt|version|unsupported version %d", ciphertext[0])
+	}
+	if len(ciphertext) < 1+nonceSize+tagSize {
+		return nil, fmt.Errorf("err:mencrypt.Decrypt|format|ciphertext too short")
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.Decrypt|key|%w", err)
+	}
+	plain, err := gcm.Open(nil, ciphertext[1:1+nonceSize], ciphertext[1+nonceSize:], ciphertext[:1])
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.Decrypt|open|%w", err)
+	}
+	return plain, nil
+}
+
+// EncryptBase64 与 Encrypt 相同，返回标准 base64 编码的字符串。
+func EncryptBase64(key, plaintext []byte) (string, error) {
+	c, err := Encrypt(key, plaintext)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(c), nil
+}
+
+// DecryptBase64 解密 EncryptBase64 生成的字符串。
+func DecryptBase64(key []byte, s string) ([]byte, error) {
+	c, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.DecryptBase64|decode|%w", err)
+	}
+	return Decrypt(key, c)
+}
+
+// EncryptHex 与 Encrypt 相同，返回小写十六进制字符串。
+func EncryptHex(key, plaintext []byte) (string, error) {
+	c, err := Encrypt(key, plaintext)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(c), nil
+}
+
+// DecryptHex 解密 EncryptHex 生成的字符串。
+func DecryptHex(key []byte, s string) ([]byte, error) {
+	c, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.DecryptHex|decode|%w", err)
+	}
+	return Decrypt(key, c)
+}
+
+// EncryptWithPassphrase 使用口令加密，随机 salt 与迭代次数保存在密文中，解密时只需要口令。
+func EncryptWithPassphrase(passphrase string, plaintext []byte) ([]byte, error) {
+	salt, err := NewSalt()
+	if err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(passphrase, salt, KDFIterations)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.EncryptWithPassphrase|derive|%w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.EncryptWithPassphrase|key|%w", err)
+	}
+
+	head := 1 + SaltSize + 4
+	out := make([]byte, head+nonceSize, head+nonceSize+len(plaintext)+tagSize)
+	out[0] = versionPassphrase
+	copy(out[1:], salt)
+	binary.BigEndian.PutUint32(out[1+SaltSize:], KDFIterations)
+	if _, err := rand.Read(out[head:]); err != nil {
+		return nil, fmt.Errorf("err:mencrypt.EncryptWithPassphrase|nonce|%w", err)
+	}
+	return gcm.Seal(out, out[head:], plaintext, out[:head]), nil
+}
+
+// DecryptWithPassphrase 解密 EncryptWithPassphrase 生成的密文。
+func DecryptWithPassphrase(passphrase string, ciphertext []byte) ([]byte, error) {
+	head := 1 + SaltSize + 4
+	if len(ciphertext) == 0 {
+		return nil, fmt.Errorf("err:mencrypt.DecryptWithPassphrase|format|ciphertext is empty")
+	}
+	if ciphertext[0] != versionPassphrase {
+		return nil, fmt.Errorf("err:mencrypt.DecryptWithPassphrase|version|unsupported version %d", ciphertext[0])
+	}
+	if len(ciphertext) < head+nonceSize+tagSize {
+		return nil, fmt.Errorf("err:mencrypt.DecryptWithPassphrase|format|ciphertext too short")
+	}
+	salt := ciphertext[1 : 1+SaltSize]
+	iter := binary.BigEndian.Uint32(ciphertext[1+SaltSize:])
+	key, err := deriveKey(passphrase, salt, int(iter))
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.DecryptWithPassphrase|derive|%w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.DecryptWithPassphrase|key|%w", err)
+	}
+	plain, err := gcm.Open(nil, ciphertext[head:head+nonceSize], ciphertext[head+nonceSize:], ciphertext[:head])
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.DecryptWithPassphrase|open|%w", err)
+	}
+	return plain, nil
+}
+
+// deriveKey 使用指定迭代次数派生密钥，iter 来自密文时需要校验范围。
+func deriveKey(passphrase string, salt []byte, iter int) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is empty")
+	}
+	if len(salt) < 8 {
+		return nil, fmt.Errorf("salt must be at least 8 bytes")
+	}
+	if iter < 1 || iter > 10*KDFIterations {
+		return nil, fmt.Errorf("invalid iteration count %d", iter)
+	}
+	return pbkdf2.Key(sha256.New, passphrase, salt, iter, KeySize)
+}
+
+// newGCM 使用 32 字节密钥创建 AES-GCM。
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,98 @@
+package mencrypt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// go test -v -run TestEncryptDecrypt
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := []byte("db-password")
+
+	c1, err := Encrypt(key, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, _ := Encrypt(key, plain)
+	if bytes.Equal(c1, c2) {
+		t.Fatal("同一明文两次加密结果不应相同")
+	}
+	if c1[0] != versionKey {
+		t.Fatalf("版本号为 %d，期望 %d", c1[0], versionKey)
+	}
+	got, err := Decrypt(key, c1)
+	if err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("Decrypt = %q, %v", got, err)
+	}
+
+	// 篡改、错误密钥、错误长度的密钥都应失败
+	c1[len(c1)-1] ^= 1
+	if _, err := Decrypt(key, c1); err == nil || !strings.HasPrefix(err.Error(), "err:mencrypt.Decrypt|open|") {
+		t.Errorf("篡改后 Decrypt error = %v", err)
+	}
+	other, _ := NewKey()
+	if _, err := Decrypt(other, c2); err == nil {
+		t.Error("错误密钥 Decrypt 应失败")
+	}
+	if _, err := Encrypt(key[:16], plain); err == nil {
+		t.Error("16 字节密钥 Encrypt 应失败")
+	}
+	if _, err := Decrypt(key, c2[:10]); err == nil {
+		t.Error("过短密文 Decrypt 应失败")
+	}
+
+	s, err := EncryptBase64(key, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DecryptBase64(key, s); err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("DecryptBase64 = %q, %v", got, err)
+	}
+	h, err := EncryptHex(key, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DecryptHex(key, h); err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("DecryptHex = %q, %v", got, err)
+	}
+	if _, err := DecryptHex(key, "zz"); err == nil {
+		t.Error("非法十六进制 DecryptHex 应失败")
+	}
+}
+
+// go test -v -run TestPassphrase
+func TestPassphrase(t *testing.T) {
+	salt, _ := NewSalt()
+	k1, err := DeriveKey("my passphrase", salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k2, _ := DeriveKey("my passphrase", salt)
+	if len(k1) != KeySize || !bytes.Equal(k1, k2) {
+		t.Fatal("同一口令与 salt 应派生出相同的 32 字节密钥")
+	}
+	if _, err := DeriveKey("", salt); err == nil {
+		t.Error("空口令应失败")
+	}
+
+	plain := []byte("secret")
+	c, err := EncryptWithPassphrase("my passphrase", plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DecryptWithPassphrase("my passphrase", c); err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("DecryptWithPassphrase = %q, %v", got, err)
+	}
+	if _, err := DecryptWithPassphrase("wrong", c); err == nil {
+		t.Error("错误口令应失败")
+	}
+	// 两种格式不能混用
+	if _, err := Decrypt(k1, c); err == nil || !strings.Contains(err.Error(), "|version|") {
+		t.Errorf("用密钥解密口令密文 error = %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package mencrypt
 
 import (
 	"testing"
+	"time"
 )
 
 // go test -v -run TestMo7
@@ -19,3 +20,35 @@ func TestMo7(t *testing.T) {
 		t.Fatal("TimeID 生成重复")
 	}
 }
+
+// go test -v -run TestUUIDv7
+func TestUUIDv7(t *testing.T) {
+	before := time.Now().UnixMilli()
+	prev := UUIDv7()
+	for i := 0; i < 1000; i++ {
+		id := UUIDv7()
+		if id <= prev {
+			t.Fatalf("UUIDv7 未递增: %s <= %s", id, prev)
+		}
+		prev = id
+	}
+	if prev[14] != '7' {
+		t.Errorf("UUIDv7 = %s, 版本号应为 7", prev)
+	}
+
+	ts, err := UUIDv7Time(prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ms := ts.ToTime().UnixMilli(); ms < before || ms > time.Now().UnixMilli() {
+		t.Errorf("UUIDv7Time = %d, want between %d and now", ms, before)
+	}
+	// RFC 9562 附录 A.6 示例
+	ts, err = UUIDv7Time("017f22e2-79b0-7cc3-98c4-dc0c0c07398f")
+	if err != nil || ts.ToTime().UnixMilli() != 0x017F22E279B0 {
+		t.Errorf("UUIDv7Time = %v, %v", ts, err)
+	}
+	if _, err := UUIDv7Time(UUID()); err == nil {
+		t.Error("v4 UUID 应返回错误")
+	}
+}
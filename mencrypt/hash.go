@@ -0,0 +1,172 @@
+package mencrypt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+/*
+摘要与 HMAC，支持字符串、[]byte、io.Reader 与文件路径，文件按流读取，不会整体载入内存。
+
+示例：
+
+	mencrypt.SHA256Hex("hello") // "2cf24dba..."
+
+	d, err := mencrypt.SumFile(mencrypt.SHA256, "./big.iso")
+	fmt.Println(d.Hex(), d.Base64())
+
+	mac, _ := mencrypt.HMAC(mencrypt.SHA256, key, body)
+	ok := mencrypt.VerifyHMACHex(mencrypt.SHA256, key, body, r.Header.Get("X-Signature"))
+
+MD5 与 SHA-1 已不再安全，仅用于校验和或兼容旧系统，请勿用于签名或存储口令。
+*/
+
+// 支持的摘要算法，即标准库 crypto.Hash 的对应取值。
+const (
+	MD5    = crypto.MD5
+	SHA1   = crypto.SHA1
+	SHA256 = crypto.SHA256
+	SHA512 = crypto.SHA512
+)
+
+// Digest 是摘要或 HMAC 的结果。
+type Digest []byte
+
+// Hex 返回小写十六进制表示。
+func (d Digest) Hex() string {
+	return hex.EncodeToString(d)
+}
+
+// Base64 返回标准 base64 表示。
+func (d Digest) Base64() string {
+	return base64.StdEncoding.EncodeToString(d)
+}
+
+// Sum 计算 data 的摘要，h 为 MD5、SHA1、SHA256 或 SHA512。
+func Sum(h crypto.Hash, data []byte) (Digest, error) {
+	fn, err := hashFunc(h)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.Sum|hash|%w", err)
+	}
+	w := fn()
+	w.Write(data)
+	return w.Sum(nil), nil
+}
+
+// SumString 计算字符串的摘要。
+func SumString(h crypto.Hash, s string) (Digest, error) {
+	return Sum(h, []byte(s))
+}
+
+// SumReader 流式计算 r 中全部内容的摘要。
+func SumReader(h crypto.Hash, r io.Reader) (Digest, error) {
+	fn, err := hashFunc(h)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.SumReader|hash|%w", err)
+	}
+	w := fn()
+	if _, err := io.Copy(w, r); err != nil {
+		return nil, fmt.Errorf("err:mencrypt.SumReader|read|%w", err)
+	}
+	return w.Sum(nil), nil
+}
+
+// SumFile 流式计算文件的摘要，适合大文件。
+func SumFile(h crypto.Hash, path string) (Digest, error) {
+	if path == "" {
+		return nil, fmt.Errorf("err:mencrypt.SumFile|path|file path empty")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.SumFile|open|%w", err)
+	}
+	defer f.Close()
+	return SumReader(h, f)
+}
+
+// MD5Hex 返回字符串 MD5 摘要的十六进制表示。
+func MD5Hex(s string) string {
+	d := md5.Sum([]byte(s))
+	return hex.EncodeToString(d[:])
+}
+
+// SHA1Hex 返回字符串 SHA-1 摘要的十六进制表示。
+func SHA1Hex(s string) string {
+	d := sha1.Sum([]byte(s))
+	return hex.EncodeToString(d[:])
+}
+
+// SHA256Hex 返回字符串 SHA-256 摘要的十六进制表示。
+func SHA256Hex(s string) string {
+	d := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(d[:])
+}
+
+// SHA512Hex 返回字符串 SHA-512 摘要的十六进制表示。
+func SHA512Hex(s string) string {
+	d := sha512.Sum512([]byte(s))
+	return hex.EncodeToString(d[:])
+}
+
+// HMAC 使用 key 计算 data 的 HMAC。
+func HMAC(h crypto.Hash, key, data []byte) (Digest, error) {
+	fn, err := hashFunc(h)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.HMAC|hash|%w", err)
+	}
+	m := hmac.New(fn, key)
+	m.Write(data)
+	return m.Sum(nil), nil
+}
+
+// VerifyHMAC 校验 mac 是否为 data 的 HMAC，使用常数时间比较以避免时序攻击。
+func VerifyHMAC(h crypto.Hash, key, data, mac []byte) bool {
+	want, err := HMAC(h, key, data)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(want, mac)
+}
+
+// VerifyHMACHex 与 VerifyHMAC 相同，mac 为十六进制字符串（不区分大小写）。
+func VerifyHMACHex(h crypto.Hash, key, data []byte, mac string) bool {
+	b, err := hex.DecodeString(mac)
+	if err != nil {
+		return false
+	}
+	return VerifyHMAC(h, key, data, b)
+}
+
+// VerifyHMACBase64 与 VerifyHMAC 相同，mac 为标准 base64 字符串。
+func VerifyHMACBase64(h crypto.Hash, key, data []byte, mac string) bool {
+	b, err := base64.StdEncoding.DecodeString(mac)
+	if err != nil {
+		return false
+	}
+	return VerifyHMAC(h, key, data, b)
+}
+
+// hashFunc 返回支持的算法的构造函数。
+func hashFunc(h crypto.Hash) (func() hash.Hash, error) {
+	switch h {
+	case MD5:
+		return md5.New, nil
+	case SHA1:
+		return sha1.New, nil
+	case SHA256:
+		return sha256.New, nil
+	case SHA512:
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("unsupported hash %v", h)
+}
@@ -0,0 +1,79 @@
+package mencrypt
+
+import (
+	"crypto"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/m-startgo/go-utils/mfile"
+)
+
+// go test -v -run TestSum
+func TestSum(t *testing.T) {
+	cases := []struct {
+		h    crypto.Hash
+		want string
+		hex  func(string) string
+	}{
+		{MD5, "5d41402abc4b2a76b9719d911017c592", MD5Hex},
+		{SHA1, "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d", SHA1Hex},
+		{SHA256, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", SHA256Hex},
+		{SHA512, "9b71d224bd62f3785d96d46ad3ea3d73319bfbc2890caadae2dff72519673ca72323c3d99ba5c11d7c7acc6e14b8c5da0c4663475c2e5c3adef46f73bcdec043", SHA512Hex},
+	}
+	path := filepath.Join(t.TempDir(), "hello.txt")
+	if err := mfile.Write(path, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		if got := c.hex("hello"); got != c.want {
+			t.Errorf("%v Hex = %s, want %s", c.h, got, c.want)
+		}
+		d, err := SumString(c.h, "hello")
+		if err != nil || d.Hex() != c.want {
+			t.Errorf("%v SumString = %s, %v", c.h, d.Hex(), err)
+		}
+		d, err = SumReader(c.h, strings.NewReader("hello"))
+		if err != nil || d.Hex() != c.want {
+			t.Errorf("%v SumReader = %s, %v", c.h, d.Hex(), err)
+		}
+		d, err = SumFile(c.h, path)
+		if err != nil || d.Hex() != c.want {
+			t.Errorf("%v SumFile = %s, %v", c.h, d.Hex(), err)
+		}
+	}
+
+	d, _ := Sum(SHA256, []byte("hello"))
+	if got := d.Base64(); got != "LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=" {
+		t.Errorf("Base64 = %s", got)
+	}
+	if _, err := Sum(crypto.SHA3_256, nil); err == nil {
+		t.Error("不支持的算法应返回错误")
+	}
+	if _, err := SumFile(SHA256, filepath.Join(t.TempDir(), "missing")); err == nil || !strings.HasPrefix(err.Error(), "err:mencrypt.SumFile|open|") {
+		t.Errorf("不存在的文件 error = %v", err)
+	}
+}
+
+// go test -v -run TestHMAC
+func TestHMAC(t *testing.T) {
+	// RFC 4231 测试用例 2
+	key, data := []byte("Jefe"), []byte("what do ya want for nothing?")
+	want := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	mac, err := HMAC(SHA256, key, data)
+	if err != nil || mac.Hex() != want {
+		t.Fatalf("HMAC = %s, %v", mac.Hex(), err)
+	}
+	if !VerifyHMAC(SHA256, key, data, mac) {
+		t.Error("VerifyHMAC = false")
+	}
+	if !VerifyHMACHex(SHA256, key, data, strings.ToUpper(want)) {
+		t.Error("VerifyHMACHex = false")
+	}
+	if !VerifyHMACBase64(SHA256, key, data, mac.Base64()) {
+		t.Error("VerifyHMACBase64 = false")
+	}
+	if VerifyHMAC(SHA256, []byte("other"), data, mac) || VerifyHMACHex(SHA256, key, data, "zz") || VerifyHMAC(SHA256, key, data, mac[:8]) {
+		t.Error("错误的 key 或 mac 校验应失败")
+	}
+}
@@ -0,0 +1,445 @@
+package mencrypt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+JWT（RFC 7519）的签发与校验，支持 HS256、RS256、ES256 与 EdDSA。
+
+自定义 claims 只需嵌入 JWTClaims，ParseJWT 通过泛型直接返回该类型：
+
+	type UserClaims struct {
+		mencrypt.JWTClaims
+		UserID int64  `json:"uid"`
+		Role   string `json:"role"`
+	}
+
+	key := mencrypt.JWTKey{ID: "2024-01", Alg: mencrypt.ES256, Key: ecKey}
+	token, _ := mencrypt.SignJWT(UserClaims{
+		JWTClaims: mencrypt.JWTClaims{Issuer: "auth", Audience: mencrypt.Audience{"api"}, ExpiresAt: time.Now().Add(time.Hour).Unix()},
+		UserID:    42,
+	}, key)
+
+	keys := mencrypt.NewJWTKeySet(key) // 按 kid 查找密钥，轮换时 Add 新密钥、稍后 Remove 旧密钥
+	c, err := mencrypt.ParseJWT[UserClaims](token, mencrypt.JWTVerifyOption{
+		Keys: keys, Issuer: "auth", Audience: "api", Skew: 30 * time.Second,
+	})
+
+校验时只接受密钥 Alg 指定的算法，token 头中的 alg 与之不符时直接拒绝，避免算法混淆攻击。
+*/
+
+// 支持的 JWT 签名算法。
+const (
+	HS256 = "HS256" // HMAC-SHA256，密钥为至少 32 字节的 []byte
+	RS256 = "RS256" // RSA PKCS#1 v1.5 + SHA-256
+	ES256 = "ES256" // ECDSA P-256 + SHA-256
+	EdDSA = "EdDSA" // Ed25519
+)
+
+// ParseJWT 返回的错误可用 errors.Is 判断原因。
+var (
+	ErrJWTMalformed   = errors.New("token malformed")
+	ErrJWTKeyNotFound = errors.New("key not found")
+	ErrJWTAlgorithm   = errors.New("algorithm mismatch")
+	ErrJWTSignature   = errors.New("signature invalid")
+	ErrJWTExpired     = errors.New("token expired")
+	ErrJWTNotValidYet = errors.New("token not valid yet")
+	ErrJWTIssuer      = errors.New("issuer mismatch")
+	ErrJWTAudience    = errors.New("audience mismatch")
+)
+
+// JWTHeader 是 JWT 的头部。
+type JWTHeader struct {
+	Alg  string   `json:"alg"`
+	Typ  string   `json:"typ,omitempty"`
+	Kid  string   `json:"kid,omitempty"`
+	Crit []string `json:"crit,omitempty"`
+}
+
+// JWTClaims 是 RFC 7519 注册的标准 claims，时间字段为 Unix 秒，0 表示未设置。
+type JWTClaims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+}
+
+// Registered 返回标准 claims，嵌入 JWTClaims 的类型由此满足 JWTClaimsGetter。
+func (c JWTClaims) Registered() JWTClaims {
+	return c
+}
+
+// JWTClaimsGetter 是 ParseJWT 类型参数的约束，通常通过嵌入 JWTClaims 实现。
+type JWTClaimsGetter interface {
+	Registered() JWTClaims
+}
+
+// Audience 是 aud claim，JSON 中可以是字符串或字符串数组，只有一个元素时编码为字符串。
+type Audience []string
+
+// MarshalJSON 实现 json.Marshaler。
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler。
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return fmt.Errorf("aud must be a string or an array of strings")
+	}
+	*a = list
+	return nil
+}
+
+// Contains 判断 aud 中是否包含 s。
+func (a Audience) Contains(s string) bool {
+	return slices.Contains(a, s)
+}
+
+/*
+JWTKey 是签发或校验 JWT 使用的密钥：
+
+  - HS256：Key 为 []byte，签发与校验使用同一密钥
+  - RS256：签发使用 *rsa.PrivateKey，校验使用 *rsa.PublicKey
+  - ES256：签发使用 P-256 的 *ecdsa.PrivateKey，校验使用 *ecdsa.PublicKey
+  - EdDSA：签发使用 ed25519.PrivateKey，校验使用 ed25519.PublicKey
+
+校验时也可以直接传入私钥。ID 非空时写入 token 头的 kid。
+*/
+type JWTKey struct {
+	ID  string
+	Alg string
+	Key any
+}
+
+// ResolveKey 实现 JWTKeyResolver，单个密钥可直接作为 JWTVerifyOption.Keys 使用。
+// k.ID 非空时 token 的 kid 必须与之相同。
+func (k JWTKey) ResolveKey(kid string) (JWTKey, error) {
+	if k.ID != "" && kid != k.ID {
+		return JWTKey{}, ErrJWTKeyNotFound
+	}
+	return k, nil
+}
+
+// JWTKeyResolver 根据 token 头中的 kid 查找校验密钥，找不到时应返回 ErrJWTKeyNotFound。
+type JWTKeyResolver interface {
+	ResolveKey(kid string) (JWTKey, error)
+}
+
+// JWTKeyResolverFunc 让普通函数实现 JWTKeyResolver，例如从 JWKS 接口或数据库加载密钥。
+type JWTKeyResolverFunc func(kid string) (JWTKey, error)
+
+// ResolveKey 实现 JWTKeyResolver。
+func (f JWTKeyResolverFunc) ResolveKey(kid string) (JWTKey, error) {
+	return f(kid)
+}
+
+// JWTKeySet 是按 kid 索引的密钥集合，并发安全，用于密钥轮换。
+// 零值可以直接使用；nil 的 *JWTKeySet 视为空集合。
+type JWTKeySet struct {
+	mu   sync.RWMutex
+	keys map[string]JWTKey
+}
+
+// NewJWTKeySet 创建密钥集合，ID 为空的密钥会被忽略。
+func NewJWTKeySet(keys ...JWTKey) *JWTKeySet {
+	s := &JWTKeySet{keys: make(map[string]JWTKey, len(keys))}
+	for _, k := range keys {
+		_ = s.Add(k)
+	}
+	return s
+}
+
+// Add 添加或替换密钥，k.ID 不能为空。
+func (s *JWTKeySet) Add(k JWTKey) error {
+	if s == nil {
+		return fmt.Errorf("err:mencrypt.JWTKeySet.Add|set|key set is nil")
+	}
+	if k.ID == "" {
+		return fmt.Errorf("err:mencrypt.JWTKeySet.Add|id|key ID is empty")
+	}
+	s.mu.Lock()
+	if s.keys == nil {
+		s.keys = map[string]JWTKey{}
+	}
+	s.keys[k.ID] = k
+	s.mu.Unlock()
+	return nil
+}
+
+// Remove 移除密钥，使用该密钥签发的 token 将无法通过校验。
+func (s *JWTKeySet) Remove(id string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	delete(s.keys, id)
+	s.mu.Unlock()
+}
+
+// ResolveKey 实现 JWTKeyResolver。
+func (s *JWTKeySet) ResolveKey(kid string) (JWTKey, error) {
+	if s == nil {
+		return JWTKey{}, ErrJWTKeyNotFound
+	}
+	s.mu.RLock()
+	k, ok := s.keys[kid]
+	s.mu.RUnlock()
+	if !ok {
+		return JWTKey{}, ErrJWTKeyNotFound
+	}
+	return k, nil
+}
+
+// JWTVerifyOption 是 ParseJWT 的校验参数。
+type JWTVerifyOption struct {
+	Keys       JWTKeyResolver   // 必填，校验密钥，可以是 JWTKey、*JWTKeySet 或 JWTKeyResolverFunc
+	Issuer     string           // 非空时 iss 必须相同
+	Audience   string           // 非空时 aud 必须包含该值
+	Skew       time.Duration    // 校验 exp、nbf、iat 时允许的时钟偏差
+	RequireExp bool             // 为 true 时 token 必须包含 exp
+	Now        func() time.Time // 当前时间，默认 time.Now，测试时可替换
+}
+
+// SignJWT 使用 key 签发 JWT，claims 会被编码为 JSON 作为 payload。
+func SignJWT(claims any, key JWTKey) (string, error) {
+	header, err := json.Marshal(JWTHeader{Alg: key.Alg, Typ: "JWT", Kid: key.ID})
+	if err != nil {
+		return "", fmt.Errorf("err:mencrypt.SignJWT|header|%w", err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("err:mencrypt.SignJWT|claims|%w", err)
+	}
+	enc := base64.RawURLEncoding
+	input := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	sig, err := jwtSign(key.Alg, key.Key, []byte(input))
+	if err != nil {
+		return "", fmt.Errorf("err:mencrypt.SignJWT|sign|%w", err)
+	}
+	return input + "." + enc.EncodeToString(sig), nil
+}
+
+// ParseJWT 校验 token 的签名与标准 claims，并将 payload 解析为 T，校验失败时返回 T 的零值。
+func ParseJWT[T JWTClaimsGetter](token string, opt JWTVerifyOption) (T, error) {
+	var claims, zero T
+	if opt.Keys == nil {
+		return zero, fmt.Errorf("err:mencrypt.ParseJWT|option|Keys is nil")
+	}
+	payload, err := verifyJWT(token, opt.Keys)
+	if err != nil {
+		return zero, err
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return zero, fmt.Errorf("err:mencrypt.ParseJWT|claims|%w: %v", ErrJWTMalformed, err)
+	}
+	if err := validateJWTClaims(claims.Registered(), opt); err != nil {
+		return zero, err
+	}
+	return claims, nil
+}
+
+// verifyJWT 解析 token 并校验签名，返回 payload 的 JSON。
+func verifyJWT(token string, keys JWTKeyResolver) ([]byte, error) {
+	var header JWTHeader
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("err:mencrypt.ParseJWT|format|%w: want 3 segments, got %d", ErrJWTMalformed, len(parts))
+	}
+	enc := base64.RawURLEncoding
+	hb, err := enc.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.ParseJWT|header|%w: %v", ErrJWTMalformed, err)
+	}
+	if err := json.Unmarshal(hb, &header); err != nil {
+		return nil, fmt.Errorf("err:mencrypt.ParseJWT|header|%w: %v", ErrJWTMalformed, err)
+	}
+	if len(header.Crit) > 0 {
+		return nil, fmt.Errorf("err:mencrypt.ParseJWT|header|%w: unsupported crit %v", ErrJWTMalformed, header.Crit)
+	}
+	payload, err := enc.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.ParseJWT|payload|%w: %v", ErrJWTMalformed, err)
+	}
+	sig, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.ParseJWT|signature|%w: %v", ErrJWTMalformed, err)
+	}
+
+	key, err := keys.ResolveKey(header.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.ParseJWT|key|kid %q: %w", header.Kid, err)
+	}
+	if key.Alg == "" || header.Alg != key.Alg {
+		return nil, fmt.Errorf("err:mencrypt.ParseJWT|alg|%w: token %q, key %q", ErrJWTAlgorithm, header.Alg, key.Alg)
+	}
+	input := token[:len(parts[0])+1+len(parts[1])]
+	if err := jwtVerify(key.Alg, key.Key, []byte(input), sig); err != nil {
+		return nil, fmt.Errorf("err:mencrypt.ParseJWT|signature|%w", err)
+	}
+	return payload, nil
+}
+
+// validateJWTClaims 校验 exp、nbf、iat、iss 与 aud。
+func validateJWTClaims(c JWTClaims, opt JWTVerifyOption) error {
+	now := time.Now()
+	if opt.Now != nil {
+		now = opt.Now()
+	}
+	if c.ExpiresAt == 0 && opt.RequireExp {
+		return fmt.Errorf("err:mencrypt.ParseJWT|exp|%w: missing exp", ErrJWTExpired)
+	}
+	if c.ExpiresAt != 0 && !now.Add(-opt.Skew).Before(time.Unix(c.ExpiresAt, 0)) {
+		return fmt.Errorf("err:mencrypt.ParseJWT|exp|%w", ErrJWTExpired)
+	}
+	if c.NotBefore != 0 && now.Add(opt.Skew).Before(time.Unix(c.NotBefore, 0)) {
+		return fmt.Errorf("err:mencrypt.ParseJWT|nbf|%w", ErrJWTNotValidYet)
+	}
+	if c.IssuedAt != 0 && now.Add(opt.Skew).Before(time.Unix(c.IssuedAt, 0)) {
+		return fmt.Errorf("err:mencrypt.ParseJWT|iat|%w: issued in the future", ErrJWTNotValidYet)
+	}
+	if opt.Issuer != "" && c.Issuer != opt.Issuer {
+		return fmt.Errorf("err:mencrypt.ParseJWT|iss|%w: %q", ErrJWTIssuer, c.Issuer)
+	}
+	if opt.Audience != "" && !c.Audience.Contains(opt.Audience) {
+		return fmt.Errorf("err:mencrypt.ParseJWT|aud|%w: %v", ErrJWTAudience, []string(c.Audience))
+	}
+	return nil
+}
+
+// jwtSign 按算法签名，key 类型必须与算法匹配。
+func jwtSign(alg string, key any, input []byte) ([]byte, error) {
+	switch alg {
+	case HS256:
+		k, ok := key.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("%s requires []byte key, got %T", alg, key)
+		}
+		if len(k) < sha256.Size {
+			return nil, fmt.Errorf("%s key must be at least %d bytes", alg, sha256.Size)
+		}
+		m := hmac.New(sha256.New, k)
+		m.Write(input)
+		return m.Sum(nil), nil
+	case RS256:
+		k, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s requires *rsa.PrivateKey, got %T", alg, key)
+		}
+		return Sign(k, input)
+	case ES256:
+		k, ok := key.(*ecdsa.PrivateKey)
+		if !ok || k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%s requires P-256 *ecdsa.PrivateKey, got %T", alg, key)
+		}
+		h := sha256.Sum256(input)
+		r, s, err := ecdsa.Sign(rand.Reader, k, h[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS 使用定长的 R || S，而不是 ASN.1
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	case EdDSA:
+		k, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s requires ed25519.PrivateKey, got %T", alg, key)
+		}
+		if len(k) != ed25519.PrivateKeySize {
+			// ed25519.Sign 遇到长度错误的密钥会 panic
+			return nil, fmt.Errorf("%s private key must be %d bytes, got %d", alg, ed25519.PrivateKeySize, len(k))
+		}
+		return ed25519.Sign(k, input), nil
+	}
+	return nil, fmt.Errorf("unsupported algorithm %q", alg)
+}
+
+// jwtVerify 按算法校验签名，签名无效时返回 ErrJWTSignature。
+func jwtVerify(alg string, key any, input, sig []byte) error {
+	if k, ok := key.(ed25519.PrivateKey); ok && len(k) != ed25519.PrivateKeySize {
+		// 长度错误的 ed25519.PrivateKey 调用 Public 会 panic
+		return fmt.Errorf("%s private key must be %d bytes, got %d", alg, ed25519.PrivateKeySize, len(k))
+	}
+	if s, ok := key.(crypto.Signer); ok {
+		key = s.Public()
+	}
+	switch alg {
+	case HS256:
+		k, ok := key.([]byte)
+		if !ok || len(k) == 0 {
+			return fmt.Errorf("%s requires []byte key, got %T", alg, key)
+		}
+		m := hmac.New(sha256.New, k)
+		m.Write(input)
+		if !hmac.Equal(m.Sum(nil), sig) {
+			return ErrJWTSignature
+		}
+	case RS256:
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s requires *rsa.PublicKey, got %T", alg, key)
+		}
+		if Verify(k, input, sig) != nil {
+			return ErrJWTSignature
+		}
+	case ES256:
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok || k.Curve != elliptic.P256() {
+			return fmt.Errorf("%s requires P-256 *ecdsa.PublicKey, got %T", alg, key)
+		}
+		if len(sig) != 64 {
+			return ErrJWTSignature
+		}
+		h := sha256.Sum256(input)
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(k, h[:], r, s) {
+			return ErrJWTSignature
+		}
+	case EdDSA:
+		k, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s requires ed25519.PublicKey, got %T", alg, key)
+		}
+		if len(k) != ed25519.PublicKeySize {
+			// ed25519.Verify 遇到长度错误的公钥会 panic
+			return fmt.Errorf("%s public key must be %d bytes, got %d", alg, ed25519.PublicKeySize, len(k))
+		}
+		if !ed25519.Verify(k, input, sig) {
+			return ErrJWTSignature
+		}
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package mencrypt
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testClaims struct {
+	JWTClaims
+	UserID int64  `json:"uid"`
+	Root   bool   `json:"http://example.com/is_root"`
+	Role   string `json:"role,omitempty"`
+}
+
+// go test -v -run TestJWT
+func TestJWT(t *testing.T) {
+	rsaKey, _ := GenerateRSAKey(2048)
+	ecKey, _ := GenerateECDSAKey()
+	edKey, _ := GenerateEd25519Key()
+	secret, _ := NewKey()
+
+	now := time.Unix(1700000000, 0)
+	claims := testClaims{
+		JWTClaims: JWTClaims{
+			Issuer:    "auth",
+			Subject:   "42",
+			Audience:  Audience{"api"},
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(time.Hour).Unix(),
+		},
+		UserID: 42,
+		Role:   "admin",
+	}
+	signKeys := []JWTKey{
+		{ID: "hs", Alg: HS256, Key: secret},
+		{ID: "rs", Alg: RS256, Key: rsaKey},
+		{ID: "es", Alg: ES256, Key: ecKey},
+		{ID: "ed", Alg: EdDSA, Key: edKey},
+	}
+	set := NewJWTKeySet(
+		JWTKey{ID: "hs", Alg: HS256, Key: secret},
+		JWTKey{ID: "rs", Alg: RS256, Key: &rsaKey.PublicKey},
+		JWTKey{ID: "es", Alg: ES256, Key: &ecKey.PublicKey},
+		JWTKey{ID: "ed", Alg: EdDSA, Key: edKey.Public()},
+	)
+	opt := JWTVerifyOption{Keys: set, Issuer: "auth", Audience: "api", Now: func() time.Time { return now }}
+	for _, key := range signKeys {
+		token, err := SignJWT(claims, key)
+		if err != nil {
+			t.Fatalf("%s SignJWT error = %v", key.Alg, err)
+		}
+		got, err := ParseJWT[testClaims](token, opt)
+		if err != nil {
+			t.Fatalf("%s ParseJWT error = %v", key.Alg, err)
+		}
+		if got.UserID != 42 || got.Role != "admin" || got.Subject != "42" || !got.Audience.Contains("api") {
+			t.Errorf("%s ParseJWT = %+v", key.Alg, got)
+		}
+
+		// 篡改 payload
+		parts := strings.Split(token, ".")
+		forged := strings.Replace(token, parts[1], base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"auth","aud":"api","uid":1}`)), 1)
+		if _, err := ParseJWT[testClaims](forged, opt); !errors.Is(err, ErrJWTSignature) {
+			t.Errorf("%s 篡改 payload error = %v, want ErrJWTSignature", key.Alg, err)
+		}
+	}
+
+	// 单个密钥直接作为 Keys 使用
+	token, _ := SignJWT(claims, signKeys[3])
+	if _, err := ParseJWT[testClaims](token, JWTVerifyOption{Keys: signKeys[3], Now: opt.Now}); err != nil {
+		t.Errorf("JWTKey 作为 Keys error = %v", err)
+	}
+
+	// 密钥移除后无法校验
+	set.Remove("ed")
+	if _, err := ParseJWT[testClaims](token, opt); !errors.Is(err, ErrJWTKeyNotFound) {
+		t.Errorf("密钥移除后 error = %v, want ErrJWTKeyNotFound", err)
+	}
+}
+
+// go test -v -run TestJWT_Claims
+func TestJWT_Claims(t *testing.T) {
+	secret, _ := NewKey()
+	key := JWTKey{Alg: HS256, Key: secret}
+	now := time.Unix(1700000000, 0)
+	sign := func(c JWTClaims) string {
+		token, err := SignJWT(testClaims{JWTClaims: c}, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	opt := func(o JWTVerifyOption) JWTVerifyOption {
+		o.Keys = key
+		o.Now = func() time.Time { return now }
+		return o
+	}
+
+	tests := []struct {
+		name   string
+		claims JWTClaims
+		opt    JWTVerifyOption
+		want   error
+	}{
+		{"有效", JWTClaims{ExpiresAt: now.Unix() + 1}, JWTVerifyOption{}, nil},
+		{"已过期", JWTClaims{ExpiresAt: now.Unix()}, JWTVerifyOption{}, ErrJWTExpired},
+		{"过期但在偏差内", JWTClaims{ExpiresAt: now.Unix() - 20}, JWTVerifyOption{Skew: 30 * time.Second}, nil},
+		{"缺少 exp", JWTClaims{}, JWTVerifyOption{RequireExp: true}, ErrJWTExpired},
+		{"尚未生效", JWTClaims{NotBefore: now.Unix() + 60}, JWTVerifyOption{}, ErrJWTNotValidYet},
+		{"nbf 在偏差内", JWTClaims{NotBefore: now.Unix() + 20}, JWTVerifyOption{Skew: 30 * time.Second}, nil},
+		{"签发时间在未来", JWTClaims{IssuedAt: now.Unix() + 60}, JWTVerifyOption{}, ErrJWTNotValidYet},
+		{"iss 不符", JWTClaims{Issuer: "other"}, JWTVerifyOption{Issuer: "auth"}, ErrJWTIssuer},
+		{"aud 不符", JWTClaims{Audience: Audience{"web"}}, JWTVerifyOption{Audience: "api"}, ErrJWTAudience},
+		{"aud 为数组", JWTClaims{Audience: Audience{"web", "api"}}, JWTVerifyOption{Audience: "api"}, nil},
+		{"缺少 aud", JWTClaims{}, JWTVerifyOption{Audience: "api"}, ErrJWTAudience},
+	}
+	for _, tt := range tests {
+		_, err := ParseJWT[testClaims](sign(tt.claims), opt(tt.opt))
+		if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+		// 错误前缀只出现一次
+		if err != nil && strings.Count(err.Error(), "err:mencrypt") != 1 {
+			t.Errorf("%s: error = %q, want a single err:mencrypt prefix", tt.name, err)
+		}
+	}
+}
+
+// go test -v -run TestJWT_Algorithm
+func TestJWT_Algorithm(t *testing.T) {
+	edKey, _ := GenerateEd25519Key()
+	pub := edKey.Public()
+	token, _ := SignJWT(JWTClaims{Subject: "x"}, JWTKey{Alg: EdDSA, Key: edKey})
+
+	// alg 与密钥不符时拒绝，例如用公钥字节作为 HMAC 密钥伪造 token
+	if _, err := ParseJWT[JWTClaims](token, JWTVerifyOption{Keys: JWTKey{Alg: HS256, Key: []byte(pub.(ed25519.PublicKey))}}); !errors.Is(err, ErrJWTAlgorithm) {
+		t.Errorf("alg 不符 error = %v, want ErrJWTAlgorithm", err)
+	}
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + strings.Split(token, ".")[1] + "."
+	if _, err := ParseJWT[JWTClaims](none, JWTVerifyOption{Keys: JWTKey{Alg: EdDSA, Key: pub}}); !errors.Is(err, ErrJWTAlgorithm) {
+		t.Errorf("alg none error = %v, want ErrJWTAlgorithm", err)
+	}
+	for _, bad := range []string{"", "a.b", "a.b.c.d", "!!.e30.x"} {
+		if _, err := ParseJWT[JWTClaims](bad, JWTVerifyOption{Keys: JWTKey{Alg: EdDSA, Key: pub}}); !errors.Is(err, ErrJWTMalformed) {
+			t.Errorf("ParseJWT(%q) error = %v, want ErrJWTMalformed", bad, err)
+		}
+	}
+	if _, err := SignJWT(JWTClaims{}, JWTKey{Alg: HS256, Key: []byte("short")}); err == nil {
+		t.Error("HS256 密钥过短时 SignJWT 应返回错误")
+	}
+	if _, err := SignJWT(JWTClaims{}, JWTKey{Alg: RS256, Key: edKey}); err == nil {
+		t.Error("密钥类型不符时 SignJWT 应返回错误")
+	}
+
+	// 长度错误的 Ed25519 密钥返回错误而不是 panic
+	short := pub.(ed25519.PublicKey)[:16]
+	if _, err := ParseJWT[JWTClaims](token, JWTVerifyOption{Keys: JWTKey{Alg: EdDSA, Key: short}}); err == nil {
+		t.Error("Ed25519 公钥长度错误时 ParseJWT 应返回错误")
+	}
+	if _, err := SignJWT(JWTClaims{}, JWTKey{Alg: EdDSA, Key: edKey[:32]}); err == nil {
+		t.Error("Ed25519 私钥长度错误时 SignJWT 应返回错误")
+	}
+	if _, err := ParseJWT[JWTClaims](token, JWTVerifyOption{Keys: JWTKey{Alg: EdDSA, Key: edKey[:16]}}); err == nil {
+		t.Error("Ed25519 私钥长度错误时 ParseJWT 应返回错误")
+	}
+
+	// nil 与零值的 *JWTKeySet 视为空集合
+	var nilSet *JWTKeySet
+	if _, err := ParseJWT[JWTClaims](token, JWTVerifyOption{Keys: nilSet}); !errors.Is(err, ErrJWTKeyNotFound) {
+		t.Errorf("nil JWTKeySet error = %v, want ErrJWTKeyNotFound", err)
+	}
+	nilSet.Remove("x")
+	if err := nilSet.Add(JWTKey{ID: "x"}); err == nil {
+		t.Error("nil JWTKeySet Add 应返回错误")
+	}
+	var set JWTKeySet
+	if err := set.Add(JWTKey{ID: "k1", Alg: EdDSA, Key: pub}); err != nil {
+		t.Errorf("零值 JWTKeySet Add error = %v", err)
+	}
+
+}
+
+// go test -v -run TestJWT_RFC7515
+func TestJWT_RFC7515(t *testing.T) {
+	// RFC 7515 附录 A.1 的 HS256 示例
+	const token = "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9" +
+		".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+		".dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	secret, _ := base64.RawURLEncoding.DecodeString("AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow")
+	opt := JWTVerifyOption{Keys: JWTKey{Alg: HS256, Key: secret}, Issuer: "joe"}
+
+	opt.Now = func() time.Time { return time.Unix(1300819379, 0) }
+	c, err := ParseJWT[testClaims](token, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Issuer != "joe" || c.ExpiresAt != 1300819380 || !c.Root {
+		t.Errorf("ParseJWT = %+v", c)
+	}
+
+	opt.Now = func() time.Time { return time.Unix(1300819380, 0) }
+	if _, err := ParseJWT[testClaims](token, opt); !errors.Is(err, ErrJWTExpired) {
+		t.Errorf("到期后 error = %v, want ErrJWTExpired", err)
+	}
+}
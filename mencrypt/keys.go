@@ -0,0 +1,272 @@
+package mencrypt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+/*
+非对称密钥：RSA、ECDSA P-256 与 Ed25519 的生成、PEM 导入导出、签名验签，以及 RSA-OAEP 加解密。
+
+私钥导出为 PKCS#8（"PRIVATE KEY"），公钥导出为 PKIX（"PUBLIC KEY"）；
+加密的私钥使用 PKCS#8 PBES2（PBKDF2-SHA256 + AES-256-CBC，"ENCRYPTED PRIVATE KEY"），
+与 openssl pkcs8 -topk8 -v2 aes-256-cbc 互通，详见 pkcs8.go。
+
+示例：
+
+	key, _ := mencrypt.GenerateEd25519Key()
+	pemBytes, _ := mencrypt.EncodeEncryptedPrivateKeyPEM(key, "passphrase")
+	pubPEM, _ := mencrypt.EncodePublicKeyPEM(key.Public())
+
+	signer, _ := mencrypt.ParseEncryptedPrivateKeyPEM(pemBytes, "passphrase")
+	sig, _ := mencrypt.Sign(signer, body)
+
+	pub, _ := mencrypt.ParsePublicKeyPEM(pubPEM)
+	err := mencrypt.Verify(pub, body, sig) // nil 表示验签通过
+*/
+
+// MinRSABits 是 GenerateRSAKey 允许的最小密钥长度。
+const MinRSABits = 2048
+
+// GenerateRSAKey 生成 RSA 私钥，bits 至少为 2048，<=0 时使用 2048。
+func GenerateRSAKey(bits int) (*rsa.PrivateKey, error) {
+	if bits <= 0 {
+		bits = MinRSABits
+	}
+	if bits < MinRSABits {
+		return nil, fmt.Errorf("err:mencrypt.GenerateRSAKey|bits|must be at least %d, got %d", MinRSABits, bits)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.GenerateRSAKey|generate|%w", err)
+	}
+	return key, nil
+}
+
+// GenerateECDSAKey 生成 ECDSA P-256 私钥。
+func GenerateECDSAKey() (*ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.GenerateECDSAKey|generate|%w", err)
+	}
+	return key, nil
+}
+
+// GenerateEd25519Key 生成 Ed25519 私钥，公钥可通过 key.Public() 获得。
+func GenerateEd25519Key() (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.GenerateEd25519Key|generate|%w", err)
+	}
+	return key, nil
+}
+
+// EncodePrivateKeyPEM 将私钥编码为 PKCS#8 PEM。
+func EncodePrivateKeyPEM(key crypto.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.EncodePrivateKeyPEM|marshal|%w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// EncodeEncryptedPrivateKeyPEM 使用口令加密私钥并编码为 PKCS#8 "ENCRYPTED PRIVATE KEY" PEM。
+func EncodeEncryptedPrivateKeyPEM(key crypto.PrivateKey, passphrase string) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.EncodeEncryptedPrivateKeyPEM|marshal|%w", err)
+	}
+	enc, err := encryptPKCS8(der, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.EncodeEncryptedPrivateKeyPEM|encrypt|%w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: enc}), nil
+}
+
+// ParsePrivateKeyPEM 解析未加密的私钥 PEM，支持 PKCS#8、PKCS#1（"RSA PRIVATE KEY"）
+// 与 SEC1（"EC PRIVATE KEY"）。加密的私钥请使用 ParseEncryptedPrivateKeyPEM。
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("err:mencrypt.ParsePrivateKeyPEM|decode|no PEM block found")
+	}
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		return nil, fmt.Errorf("err:mencrypt.ParsePrivateKeyPEM|type|key is encrypted, use ParseEncryptedPrivateKeyPEM")
+	}
+	key, err := parsePrivateKeyDER(block.Type, block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.ParsePrivateKeyPEM|parse|%w", err)
+	}
+	return key, nil
+}
+
+// ParseEncryptedPrivateKeyPEM 使用口令解析 "ENCRYPTED PRIVATE KEY" PEM，
+// 传入未加密的私钥时忽略口令，与 ParsePrivateKeyPEM 相同。
+func ParseEncryptedPrivateKeyPEM(data []byte, passphrase string) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("err:mencrypt.ParseEncryptedPrivateKeyPEM|decode|no PEM block found")
+	}
+	if block.Type != "ENCRYPTED PRIVATE KEY" {
+		return ParsePrivateKeyPEM(data)
+	}
+	der, err := decryptPKCS8(block.Bytes, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.ParseEncryptedPrivateKeyPEM|decrypt|%w", err)
+	}
+	key, err := parsePrivateKeyDER("PRIVATE KEY", der)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.ParseEncryptedPrivateKeyPEM|parse|%w", err)
+	}
+	return key, nil
+}
+
+// EncodePublicKeyPEM 将公钥编码为 PKIX PEM。
+func EncodePublicKeyPEM(pub crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.EncodePublicKeyPEM|marshal|%w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// ParsePublicKeyPEM 解析公钥 PEM，支持 PKIX（"PUBLIC KEY"）、PKCS#1（"RSA PUBLIC KEY"）
+// 以及证书（"CERTIFICATE"，返回证书中的公钥）。
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("err:mencrypt.ParsePublicKeyPEM|decode|no PEM block found")
+	}
+	var (
+		pub crypto.PublicKey
+		err error
+	)
+	switch block.Type {
+	case "PUBLIC KEY":
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			pub = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("err:mencrypt.ParsePublicKeyPEM|type|unsupported PEM type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.ParsePublicKeyPEM|parse|%w", err)
+	}
+	return pub, nil
+}
+
+/*
+Sign 使用私钥对 data 签名：
+
+  - RSA：PKCS#1 v1.5 + SHA-256
+  - ECDSA：SHA-256，签名为 ASN.1 DER 编码
+  - Ed25519：直接对 data 签名
+*/
+func Sign(key crypto.Signer, data []byte) ([]byte, error) {
+	var (
+		sig []byte
+		err error
+	)
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		h := sha256.Sum256(data)
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, h[:])
+	case *ecdsa.PrivateKey:
+		h := sha256.Sum256(data)
+		sig, err = ecdsa.SignASN1(rand.Reader, k, h[:])
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, data)
+	default:
+		return nil, fmt.Errorf("err:mencrypt.Sign|key|unsupported key type %T", key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.Sign|sign|%w", err)
+	}
+	return sig, nil
+}
+
+// Verify 使用公钥校验 Sign 生成的签名，返回 nil 表示验签通过。
+func Verify(pub crypto.PublicKey, data, sig []byte) error {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		h := sha256.Sum256(data)
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, h[:], sig); err != nil {
+			return fmt.Errorf("err:mencrypt.Verify|verify|%w", err)
+		}
+	case *ecdsa.PublicKey:
+		h := sha256.Sum256(data)
+		if !ecdsa.VerifyASN1(k, h[:], sig) {
+			return fmt.Errorf("err:mencrypt.Verify|verify|invalid signature")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, data, sig) {
+			return fmt.Errorf("err:mencrypt.Verify|verify|invalid signature")
+		}
+	default:
+		return fmt.Errorf("err:mencrypt.Verify|key|unsupported key type %T", pub)
+	}
+	return nil
+}
+
+// EncryptRSA 使用 RSA-OAEP（SHA-256）加密，明文长度不能超过 密钥字节数-66。
+func EncryptRSA(pub *rsa.PublicKey, plaintext []byte) ([]byte, error) {
+	if pub == nil {
+		return nil, fmt.Errorf("err:mencrypt.EncryptRSA|key|public key is nil")
+	}
+	c, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, plaintext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.EncryptRSA|encrypt|%w", err)
+	}
+	return c, nil
+}
+
+// DecryptRSA 解密 EncryptRSA 生成的密文。
+func DecryptRSA(key *rsa.PrivateKey, ciphertext []byte) ([]byte, error) {
+	if key == nil {
+		return nil, fmt.Errorf("err:mencrypt.DecryptRSA|key|private key is nil")
+	}
+	p, err := rsa.DecryptOAEP(sha256.New(), nil, key, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("err:mencrypt.DecryptRSA|decrypt|%w", err)
+	}
+	return p, nil
+}
+
+// parsePrivateKeyDER 按 PEM 类型解析私钥 DER，只接受可用于签名的 RSA、ECDSA 与 Ed25519 私钥。
+func parsePrivateKeyDER(typ string, der []byte) (crypto.Signer, error) {
+	var (
+		key any
+		err error
+	)
+	switch typ {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(der)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(der)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(der)
+	default:
+		return nil, fmt.Errorf("unsupported PEM type %q", typ)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey:
+		return k.(crypto.Signer), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T", key)
+}
@@ -0,0 +1,259 @@
+package mencrypt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m-startgo/go-utils/murl"
+)
+
+/*
+一次性口令：RFC 4226 HOTP 与 RFC 6238 TOTP，兼容 Google Authenticator 等验证器应用。
+
+示例：
+
+	secret, _ := mencrypt.NewOTPSecret() // 保存到用户记录中
+	uri, _ := mencrypt.TOTPURI(secret, "MyAdmin", "alice@example.com", mencrypt.OTPOption{})
+	// 将 uri 生成二维码供用户扫描
+
+	step, ok, err := mencrypt.VerifyTOTPStep(secret, code, mencrypt.OTPOption{Skew: 1})
+	if ok && step > user.LastOTPStep {
+		user.LastOTPStep = step // 记录已使用的时间步，拒绝同一验证码被重复使用
+	}
+
+secret 为 base32 字符串，不区分大小写，可以包含空格与填充。
+*/
+
+// OTPSecretSize 是 NewOTPSecret 生成的密钥字节数（160 位，RFC 4226 推荐值）。
+const OTPSecretSize = 20
+
+// OTPOption 是 HOTP/TOTP 的参数，零值字段使用默认值。生成与校验、以及写入 URI 时需使用相同参数。
+type OTPOption struct {
+	Digits    int              // 验证码位数，6 ~ 8，默认 6
+	Period    time.Duration    // TOTP 时间步长，整秒，默认 30s
+	Algorithm crypto.Hash      // SHA1（默认）、SHA256 或 SHA512
+	Skew      int              // 校验时允许的偏差：TOTP 前后各 Skew 个时间步，HOTP 向后 Skew 个计数，推荐 TOTP 为 1
+	Now       func() time.Time // 当前时间，默认 time.Now，测试时可替换
+}
+
+// NewOTPSecret 生成一个随机的 base32 密钥（不带填充）。
+func NewOTPSecret() (string, error) {
+	b := make([]byte, OTPSecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("err:mencrypt.NewOTPSecret|rand|%w", err)
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
+
+// HOTP 生成计数器 counter 对应的验证码。
+func HOTP(secret string, counter uint64, opt OTPOption) (string, error) {
+	opt, key, err := opt.prepare(secret)
+	if err != nil {
+		return "", fmt.Errorf("err:mencrypt.HOTP|option|%w", err)
+	}
+	return otpCode(key, counter, opt), nil
+}
+
+// VerifyHOTP 校验 HOTP 验证码，依次尝试 counter ~ counter+Skew。
+// 校验通过时返回下一次应使用的计数器（匹配的计数器 + 1），调用方需保存它。
+func VerifyHOTP(secret, code string, counter uint64, opt OTPOption) (uint64, bool, error) {
+	opt, key, err := opt.prepare(secret)
+	if err != nil {
+		return counter, false, fmt.Errorf("err:mencrypt.VerifyHOTP|option|%w", err)
+	}
+	if len(code) != opt.Digits {
+		return counter, false, nil
+	}
+	for i := uint64(0); i <= uint64(opt.Skew); i++ {
+		if subtle.ConstantTimeCompare([]byte(otpCode(key, counter+i, opt)), []byte(code)) == 1 {
+			return counter + i + 1, true, nil
+		}
+	}
+	return counter, false, nil
+}
+
+// TOTP 生成当前时间对应的验证码。
+func TOTP(secret string, opt OTPOption) (string, error) {
+	opt, key, err := opt.prepare(secret)
+	if err != nil {
+		return "", fmt.Errorf("err:mencrypt.TOTP|option|%w", err)
+	}
+	return otpCode(key, uint64(opt.step()), opt), nil
+}
+
+// VerifyTOTP 校验 TOTP 验证码，允许前后各 Skew 个时间步的偏差。
+// 同一验证码在有效期内可以重复通过，需要防重放时请使用 VerifyTOTPStep。
+func VerifyTOTP(secret, code string, opt OTPOption) (bool, error) {
+	_, ok, err := VerifyTOTPStep(secret, code, opt)
+	return ok, err
+}
+
+// VerifyTOTPStep 与 VerifyTOTP 相同，同时返回匹配的时间步（Unix 时间 / Period）。
+// 调用方保存最后一次通过的时间步，只接受更大的时间步即可防止验证码被重复使用。
+func VerifyTOTPStep(secret, code string, opt OTPOption) (int64, bool, error) {
+	opt, key, err := opt.prepare(secret)
+	if err != nil {
+		return 0, false, fmt.Errorf("err:mencrypt.VerifyTOTPStep|option|%w", err)
+	}
+	if len(code) != opt.Digits {
+		return 0, false, nil
+	}
+	now := opt.step()
+	for i := -int64(opt.Skew); i <= int64(opt.Skew); i++ {
+		step := now + i
+		if step < 0 {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(otpCode(key, uint64(step), opt)), []byte(code)) == 1 {
+			return step, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// TOTPURI 生成验证器应用使用的 otpauth://totp/ 配置 URI，通常转为二维码供用户扫描。
+func TOTPURI(secret, issuer, account string, opt OTPOption) (string, error) {
+	s, err := otpURI("totp", secret, issuer, account, nil, opt)
+	if err != nil {
+		return "", fmt.Errorf("err:mencrypt.TOTPURI|uri|%w", err)
+	}
+	return s, nil
+}
+
+// HOTPURI 生成 otpauth://hotp/ 配置 URI，counter 为验证器的初始计数器。
+func HOTPURI(secret, issuer, account string, counter uint64, opt OTPOption) (string, error) {
+	s, err := otpURI("hotp", secret, issuer, account, &counter, opt)
+	if err != nil {
+		return "", fmt.Errorf("err:mencrypt.HOTPURI|uri|%w", err)
+	}
+	return s, nil
+}
+
+// otpURI 按 Google Authenticator 的 Key Uri Format 生成 URI。
+func otpURI(typ, secret, issuer, account string, counter *uint64, opt OTPOption) (string, error) {
+	if account == "" {
+		return "", fmt.Errorf("account is empty")
+	}
+	if strings.Contains(issuer, ":") {
+		return "", fmt.Errorf("issuer must not contain ':'")
+	}
+	opt, key, err := opt.prepare(secret)
+	if err != nil {
+		return "", err
+	}
+	u, err := murl.Parse("otpauth://" + typ + "/")
+	if err != nil {
+		return "", err
+	}
+
+	// label 的各部分分别转义，account 中的 '/'、':' 不会被当作路径分隔符或 issuer 分隔符
+	label, rawLabel := account, otpLabelEscape(account)
+	if issuer != "" {
+		label = issuer + ":" + account
+		rawLabel = otpLabelEscape(issuer) + ":" + rawLabel
+	}
+	q := url.Values{}
+	q.Set("secret", base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key))
+	if issuer != "" {
+		q.Set("issuer", issuer)
+	}
+	q.Set("algorithm", otpAlgorithmName(opt.Algorithm))
+	q.Set("digits", strconv.Itoa(opt.Digits))
+	if counter != nil {
+		q.Set("counter", strconv.FormatUint(*counter, 10))
+	} else {
+		q.Set("period", strconv.Itoa(int(opt.Period/time.Second)))
+	}
+
+	raw := u.URL()
+	raw.Path, raw.RawPath = "/"+label, "/"+rawLabel
+	// 部分验证器不会把 '+' 解码为空格
+	raw.RawQuery = strings.ReplaceAll(q.Encode(), "+", "%20")
+	return u.String(), nil
+}
+
+// otpLabelEscape 转义 label 的一部分，url.PathEscape 不转义的 ':' 也一并转义。
+func otpLabelEscape(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), ":", "%3A")
+}
+
+// prepare 校验参数、填充默认值并解码 secret。
+func (o OTPOption) prepare(secret string) (OTPOption, []byte, error) {
+	if o.Digits == 0 {
+		o.Digits = 6
+	}
+	if o.Digits < 6 || o.Digits > 8 {
+		return o, nil, fmt.Errorf("digits must be between 6 and 8, got %d", o.Digits)
+	}
+	if o.Period == 0 {
+		o.Period = 30 * time.Second
+	}
+	if o.Period < time.Second || o.Period%time.Second != 0 {
+		return o, nil, fmt.Errorf("period must be a whole number of seconds, got %s", o.Period)
+	}
+	if o.Algorithm == 0 {
+		o.Algorithm = SHA1
+	}
+	if otpAlgorithmName(o.Algorithm) == "" {
+		return o, nil, fmt.Errorf("unsupported algorithm %v", o.Algorithm)
+	}
+	if o.Skew < 0 {
+		return o, nil, fmt.Errorf("skew must not be negative, got %d", o.Skew)
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+
+	s := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(secret))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return o, nil, fmt.Errorf("invalid base32 secret: %w", err)
+	}
+	if len(key) < 10 {
+		return o, nil, fmt.Errorf("secret must be at least 10 bytes, got %d", len(key))
+	}
+	return o, key, nil
+}
+
+// step 返回当前时间所在的 TOTP 时间步。
+func (o OTPOption) step() int64 {
+	return o.Now().Unix() / int64(o.Period/time.Second)
+}
+
+// otpCode 按 RFC 4226 动态截断计算验证码，opt 需已 prepare。
+func otpCode(key []byte, counter uint64, opt OTPOption) string {
+	fn, _ := hashFunc(opt.Algorithm)
+	m := hmac.New(fn, key)
+	binary.Write(m, binary.BigEndian, counter)
+	sum := m.Sum(nil)
+	off := sum[len(sum)-1] & 0x0F
+	v := binary.BigEndian.Uint32(sum[off:]) & 0x7FFFFFFF
+
+	mod := uint32(1)
+	for i := 0; i < opt.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", opt.Digits, v%mod)
+}
+
+// otpAlgorithmName 返回 URI 中的算法名，不支持的算法返回空字符串。
+func otpAlgorithmName(h crypto.Hash) string {
+	switch h {
+	case SHA1:
+		return "SHA1"
+	case SHA256:
+		return "SHA256"
+	case SHA512:
+		return "SHA512"
+	}
+	return ""
+}
@@ -0,0 +1,180 @@
+package mencrypt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-startgo/go-utils/murl"
+)
+
+// RFC 4226 / RFC 6238 附录中的密钥，base32 编码
+const (
+	otpSecretSHA1   = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	otpSecretSHA256 = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZA===="
+	otpSecretSHA512 = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNA="
+)
+
+// go test -v -run TestHOTP
+func TestHOTP(t *testing.T) {
+	// RFC 4226 附录 D
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for i, w := range want {
+		got, err := HOTP(otpSecretSHA1, uint64(i), OTPOption{})
+		if err != nil || got != w {
+			t.Errorf("HOTP(%d) = %s, %v, want %s", i, got, err, w)
+		}
+	}
+
+	next, ok, err := VerifyHOTP(otpSecretSHA1, "969429", 1, OTPOption{Skew: 2})
+	if !ok || err != nil || next != 4 {
+		t.Errorf("VerifyHOTP = %d, %v, %v, want 4, true", next, ok, err)
+	}
+	if _, ok, _ := VerifyHOTP(otpSecretSHA1, "969429", 1, OTPOption{Skew: 1}); ok {
+		t.Error("超出 Skew 时 VerifyHOTP 应返回 false")
+	}
+	if _, ok, _ := VerifyHOTP(otpSecretSHA1, "755224", 1, OTPOption{Skew: 5}); ok {
+		t.Error("HOTP 不应接受已使用的计数器")
+	}
+}
+
+// go test -v -run TestTOTP
+func TestTOTP(t *testing.T) {
+	// RFC 6238 附录 B，8 位验证码
+	tests := []struct {
+		unix   int64
+		sha1   string
+		sha256 string
+		sha512 string
+	}{
+		{59, "94287082", "46119246", "90693936"},
+		{1111111109, "07081804", "68084774", "25091201"},
+		{20000000000, "65353130", "77737706", "47863826"},
+	}
+	for _, tt := range tests {
+		now := func() time.Time { return time.Unix(tt.unix, 0) }
+		for _, c := range []struct {
+			secret string
+			opt    OTPOption
+			want   string
+		}{
+			{otpSecretSHA1, OTPOption{Digits: 8, Now: now}, tt.sha1},
+			{otpSecretSHA256, OTPOption{Digits: 8, Algorithm: SHA256, Now: now}, tt.sha256},
+			{otpSecretSHA512, OTPOption{Digits: 8, Algorithm: SHA512, Now: now}, tt.sha512},
+		} {
+			got, err := TOTP(c.secret, c.opt)
+			if err != nil || got != c.want {
+				t.Errorf("TOTP(%d, %v) = %s, %v, want %s", tt.unix, c.opt.Algorithm, got, err, c.want)
+			}
+			if ok, err := VerifyTOTP(c.secret, c.want, c.opt); !ok || err != nil {
+				t.Errorf("VerifyTOTP(%d, %v) = %v, %v", tt.unix, c.opt.Algorithm, ok, err)
+			}
+		}
+	}
+
+	// 偏差窗口
+	now := time.Unix(1111111109, 0)
+	opt := OTPOption{Digits: 8, Skew: 1, Now: func() time.Time { return now.Add(30 * time.Second) }}
+	step, ok, err := VerifyTOTPStep(otpSecretSHA1, "07081804", opt)
+	if !ok || err != nil || step != 1111111109/30 {
+		t.Errorf("VerifyTOTPStep = %d, %v, %v", step, ok, err)
+	}
+	opt.Now = func() time.Time { return now.Add(61 * time.Second) }
+	if ok, _ := VerifyTOTP(otpSecretSHA1, "07081804", opt); ok {
+		t.Error("超出 Skew 时 VerifyTOTP 应返回 false")
+	}
+	if ok, _ := VerifyTOTP(otpSecretSHA1, "0708180", opt); ok {
+		t.Error("位数不符时 VerifyTOTP 应返回 false")
+	}
+}
+
+// go test -v -run TestOTPSecret
+func TestOTPSecret(t *testing.T) {
+	s, err := NewOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 32 || strings.Contains(s, "=") {
+		t.Errorf("NewOTPSecret = %s, want 32 characters without padding", s)
+	}
+	code, err := TOTP(s, OTPOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 小写、空格分组的密钥视为相同
+	spaced := strings.ToLower(s[:4] + " " + s[4:8] + " " + s[8:])
+	if ok, err := VerifyTOTP(spaced, code, OTPOption{Skew: 1}); !ok || err != nil {
+		t.Errorf("VerifyTOTP(小写带空格) = %v, %v", ok, err)
+	}
+
+	for _, opt := range []OTPOption{{Digits: 5}, {Digits: 9}, {Period: 1500 * time.Millisecond}, {Algorithm: MD5}, {Skew: -1}} {
+		if _, err := TOTP(s, opt); err == nil {
+			t.Errorf("TOTP(%+v) 应返回错误", opt)
+		}
+	}
+	for _, bad := range []string{"", "not-base32!", "GEZDGNBV"} {
+		if _, err := TOTP(bad, OTPOption{}); err == nil || !strings.HasPrefix(err.Error(), "err:mencrypt.TOTP|option|") {
+			t.Errorf("TOTP(%q) error = %v", bad, err)
+		}
+	}
+}
+
+// go test -v -run TestOTPURI
+func TestOTPURI(t *testing.T) {
+	uri, err := TOTPURI(otpSecretSHA256, "My Admin", "alice@example.com", OTPOption{Digits: 8, Algorithm: SHA256, Period: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(uri, "otpauth://totp/My%20Admin:alice@example.com?") || strings.Contains(uri, "+") {
+		t.Errorf("TOTPURI = %s", uri)
+	}
+	u, err := murl.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"secret":    "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZA",
+		"issuer":    "My Admin",
+		"algorithm": "SHA256",
+		"digits":    "8",
+		"period":    "60",
+	}
+	for k, v := range want {
+		if got := u.QueryValue(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+	if u.Path() != "/My Admin:alice@example.com" {
+		t.Errorf("Path = %q", u.Path())
+	}
+
+	uri, err = HOTPURI(otpSecretSHA1, "", "bob", 7, OTPOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri != "otpauth://hotp/bob?algorithm=SHA1&counter=7&digits=6&secret="+otpSecretSHA1 {
+		t.Errorf("HOTPURI = %s", uri)
+	}
+
+	// account 中的 '/' 与 ':' 被转义，不会改变路径层级或 issuer 的划分
+	uri, err = TOTPURI(otpSecretSHA1, "My Admin", "ops/alice:1", OTPOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(uri, "otpauth://totp/My%20Admin:ops%2Falice%3A1?") {
+		t.Errorf("TOTPURI = %s", uri)
+	}
+	if u, err = murl.Parse(uri); err != nil {
+		t.Fatal(err)
+	}
+	if u.Path() != "/My Admin:ops/alice:1" {
+		t.Errorf("Path = %q", u.Path())
+	}
+
+	if _, err := TOTPURI(otpSecretSHA1, "a:b", "bob", OTPOption{}); err == nil {
+		t.Error("issuer 包含 ':' 时应返回错误")
+	}
+	if _, err := TOTPURI(otpSecretSHA1, "x", "", OTPOption{}); err == nil {
+		t.Error("account 为空时应返回错误")
+	}
+}
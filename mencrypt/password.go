@@ -0,0 +1,304 @@
+package mencrypt
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+/*
+口令哈希，结果为自描述的 PHC 字符串，算法、参数、salt 与哈希值都保存在其中：
+
+	$argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+	$scrypt$ln=15,r=8,p=1$<salt>$<hash>
+	$pbkdf2-sha256$i=600000$<salt>$<hash>
+
+salt 与哈希值使用不带填充的标准 base64。默认使用 argon2id，参数取自 OWASP 推荐值。
+
+示例：
+
+	h, _ := mencrypt.HashPassword("hunter2")
+	ok, err := mencrypt.VerifyPassword("hunter2", h)
+	if ok && mencrypt.NeedsRehash(h, mencrypt.PasswordOption{}) {
+		h, _ = mencrypt.HashPassword("hunter2") // 登录成功后按新参数重新哈希并保存
+	}
+*/
+
+// 支持的口令哈希算法，取值即 PHC 字符串中的算法标识。
+const (
+	PasswordArgon2id = "argon2id"
+	PasswordScrypt   = "scrypt"
+	PasswordPBKDF2   = "pbkdf2-sha256"
+)
+
+// PasswordOption 是口令哈希的参数，零值字段使用默认值。
+type PasswordOption struct {
+	Algorithm string // PasswordArgon2id（默认）、PasswordScrypt 或 PasswordPBKDF2
+
+	Memory  uint32 // argon2id 内存，单位 KiB，默认 19456（19 MiB）
+	Time    uint32 // argon2id 迭代次数，默认 2
+	Threads uint8  // argon2id 并行度，默认 1
+
+	LogN int // scrypt 的 log2(N)，默认 15
+	R    int // scrypt 块大小，默认 8
+	P    int // scrypt 并行度，默认 1
+
+	Iterations int // pbkdf2-sha256 迭代次数，默认 600000
+
+	SaltLen int // salt 字节数，默认 16
+	KeyLen  int // 哈希值字节数，默认 32
+}
+
+// 哈希与解析 PHC 字符串时允许的参数上限，避免恶意构造的哈希消耗过多资源。
+const (
+	maxArgon2Memory  = 4 << 20 // 4 GiB
+	maxArgon2Time    = 100
+	maxScryptLogN    = 24
+	maxScryptMemory  = 1 << 30 // scrypt 占用内存 128·r·N 字节的上限，1 GiB
+	maxScryptRP      = 1 << 10 // scrypt r·p 的上限，限制 CPU 开销
+	maxPBKDF2Iter    = 10000000
+	maxSaltLen       = 64
+	maxKeyLen        = 128
+	maxPasswordBytes = 1024
+)
+
+// HashPassword 使用默认参数（argon2id）哈希口令。
+func HashPassword(password string) (string, error) {
+	return HashPasswordWith(password, PasswordOption{})
+}
+
+// HashPasswordWith 使用指定算法与参数哈希口令。
+func HashPasswordWith(password string, opt PasswordOption) (string, error) {
+	opt, err := opt.normalize()
+	if err != nil {
+		return "", fmt.Errorf("err:mencrypt.HashPassword|option|%w", err)
+	}
+	salt := make([]byte, opt.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("err:mencrypt.HashPassword|rand|%w", err)
+	}
+	key, err := passwordKey(password, salt, opt)
+	if err != nil {
+		return "", fmt.Errorf("err:mencrypt.HashPassword|%s|%w", opt.Algorithm, err)
+	}
+	return formatPHC(opt, salt, key), nil
+}
+
+// VerifyPassword 校验口令是否与 PHC 字符串匹配，使用常数时间比较。
+// 不匹配时返回 false, nil；encoded 格式错误或参数不受支持时返回错误。
+func VerifyPassword(password, encoded string) (bool, error) {
+	opt, salt, key, err := parsePHC(encoded)
+	if err != nil {
+		return false, fmt.Errorf("err:mencrypt.VerifyPassword|parse|%w", err)
+	}
+	got, err := passwordKey(password, salt, opt)
+	if err != nil {
+		return false, fmt.Errorf("err:mencrypt.VerifyPassword|%s|%w", opt.Algorithm, err)
+	}
+	return subtle.ConstantTimeCompare(got, key) == 1, nil
+}
+
+// NeedsRehash 判断 encoded 是否需要按 opt 重新哈希：算法或参数与 opt 不一致、
+// 或 encoded 无法解析时返回 true。通常在登录校验成功后调用。
+func NeedsRehash(encoded string, opt PasswordOption) bool {
+	want, err := opt.normalize()
+	if err != nil {
+		return false
+	}
+	got, _, _, err := parsePHC(encoded)
+	if err != nil {
+		return true
+	}
+	return got != want
+}
+
+// normalize 校验算法并填充默认值，只保留所选算法相关的字段，便于直接比较。
+func (o PasswordOption) normalize() (PasswordOption, error) {
+	res := PasswordOption{Algorithm: o.Algorithm, SaltLen: o.SaltLen, KeyLen: o.KeyLen}
+	if res.Algorithm == "" {
+		res.Algorithm = PasswordArgon2id
+	}
+	if res.SaltLen <= 0 {
+		res.SaltLen = 16
+	}
+	if res.KeyLen <= 0 {
+		res.KeyLen = 32
+	}
+	switch res.Algorithm {
+	case PasswordArgon2id:
+		res.Memory, res.Time, res.Threads = o.Memory, o.Time, o.Threads
+		if res.Memory == 0 {
+			res.Memory = 19456
+		}
+		if res.Time == 0 {
+			res.Time = 2
+		}
+		if res.Threads == 0 {
+			res.Threads = 1
+		}
+	case PasswordScrypt:
+		res.LogN, res.R, res.P = o.LogN, o.R, o.P
+		if res.LogN <= 0 {
+			res.LogN = 15
+		}
+		if res.R <= 0 {
+			res.R = 8
+		}
+		if res.P <= 0 {
+			res.P = 1
+		}
+	case PasswordPBKDF2:
+		res.Iterations = o.Iterations
+		if res.Iterations <= 0 {
+			res.Iterations = 600000
+		}
+	default:
+		return res, fmt.Errorf("unsupported algorithm %q", res.Algorithm)
+	}
+	return res, res.check()
+}
+
+// check 校验参数是否在允许的范围内，与解析 PHC 字符串时使用相同的上限，
+// 保证生成的哈希总能被 VerifyPassword 接受。
+func (o PasswordOption) check() error {
+	switch o.Algorithm {
+	case PasswordArgon2id:
+		if o.Memory > maxArgon2Memory || o.Time > maxArgon2Time {
+			return fmt.Errorf("argon2id parameters out of range")
+		}
+	case PasswordScrypt:
+		// 先分别限制 r、p，再计算乘积与内存，避免溢出
+		if o.LogN > maxScryptLogN || o.R > maxScryptRP || o.P > maxScryptRP || o.R*o.P > maxScryptRP ||
+			128*o.R<<o.LogN > maxScryptMemory {
+			return fmt.Errorf("scrypt parameters out of range")
+		}
+	case PasswordPBKDF2:
+		if o.Iterations > maxPBKDF2Iter {
+			return fmt.Errorf("pbkdf2 iterations out of range")
+		}
+	}
+	if o.SaltLen > maxSaltLen {
+		return fmt.Errorf("salt longer than %d bytes", maxSaltLen)
+	}
+	if o.KeyLen > maxKeyLen {
+		return fmt.Errorf("hash longer than %d bytes", maxKeyLen)
+	}
+	return nil
+}
+
+// passwordKey 按 opt 计算口令的哈希值，opt 需已 normalize。
+func passwordKey(password string, salt []byte, opt PasswordOption) ([]byte, error) {
+	if len(password) > maxPasswordBytes {
+		return nil, fmt.Errorf("password longer than %d bytes", maxPasswordBytes)
+	}
+	switch opt.Algorithm {
+	case PasswordArgon2id:
+		return argon2.IDKey([]byte(password), salt, opt.Time, opt.Memory, opt.Threads, uint32(opt.KeyLen)), nil
+	case PasswordScrypt:
+		return scrypt.Key([]byte(password), salt, 1<<opt.LogN, opt.R, opt.P, opt.KeyLen)
+	case PasswordPBKDF2:
+		return pbkdf2.Key(sha256.New, password, salt, opt.Iterations, opt.KeyLen)
+	}
+	return nil, fmt.Errorf("unsupported algorithm %q", opt.Algorithm)
+}
+
+// formatPHC 生成 PHC 字符串。
+func formatPHC(opt PasswordOption, salt, key []byte) string {
+	var params string
+	switch opt.Algorithm {
+	case PasswordArgon2id:
+		params = fmt.Sprintf("v=%d$m=%d,t=%d,p=%d", argon2.Version, opt.Memory, opt.Time, opt.Threads)
+	case PasswordScrypt:
+		params = fmt.Sprintf("ln=%d,r=%d,p=%d", opt.LogN, opt.R, opt.P)
+	case PasswordPBKDF2:
+		params = fmt.Sprintf("i=%d", opt.Iterations)
+	}
+	enc := base64.RawStdEncoding
+	return "$" + opt.Algorithm + "$" + params + "$" + enc.EncodeToString(salt) + "$" + enc.EncodeToString(key)
+}
+
+// parsePHC 解析 PHC 字符串，返回的 opt 只包含 PHC 中记录的参数以及 salt、哈希值的长度。
+// 未知或重复的参数、超出上限的参数与长度都会被拒绝。
+func parsePHC(encoded string) (opt PasswordOption, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) < 5 || parts[0] != "" {
+		return opt, nil, nil, fmt.Errorf("invalid PHC string")
+	}
+	opt.Algorithm = parts[1]
+	if opt.Algorithm == PasswordArgon2id {
+		// argon2id 多一段版本号
+		if len(parts) != 6 {
+			return opt, nil, nil, fmt.Errorf("invalid PHC string")
+		}
+		if parts[2] != fmt.Sprintf("v=%d", argon2.Version) {
+			return opt, nil, nil, fmt.Errorf("unsupported argon2 version %q", parts[2])
+		}
+		parts = append(parts[:2], parts[3:]...)
+	}
+	if len(parts) != 5 {
+		return opt, nil, nil, fmt.Errorf("invalid PHC string")
+	}
+
+	var allowed []string
+	switch opt.Algorithm {
+	case PasswordArgon2id:
+		allowed = []string{"m", "t", "p"}
+	case PasswordScrypt:
+		allowed = []string{"ln", "r", "p"}
+	case PasswordPBKDF2:
+		allowed = []string{"i"}
+	default:
+		return opt, nil, nil, fmt.Errorf("unsupported algorithm %q", opt.Algorithm)
+	}
+	params := map[string]int{}
+	for _, kv := range strings.Split(parts[2], ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !slices.Contains(allowed, k) {
+			return opt, nil, nil, fmt.Errorf("unknown parameter %q", kv)
+		}
+		if _, dup := params[k]; dup {
+			return opt, nil, nil, fmt.Errorf("duplicate parameter %q", kv)
+		}
+		n, err := strconv.Atoi(v)
+		if !ok || err != nil || n <= 0 {
+			return opt, nil, nil, fmt.Errorf("invalid parameter %q", kv)
+		}
+		params[k] = n
+	}
+	if len(params) != len(allowed) {
+		return opt, nil, nil, fmt.Errorf("missing %s parameters", opt.Algorithm)
+	}
+	switch opt.Algorithm {
+	case PasswordArgon2id:
+		if params["m"] > maxArgon2Memory || params["t"] > maxArgon2Time || params["p"] > 255 {
+			return opt, nil, nil, fmt.Errorf("argon2id parameters out of range")
+		}
+		opt.Memory, opt.Time, opt.Threads = uint32(params["m"]), uint32(params["t"]), uint8(params["p"])
+	case PasswordScrypt:
+		opt.LogN, opt.R, opt.P = params["ln"], params["r"], params["p"]
+	case PasswordPBKDF2:
+		opt.Iterations = params["i"]
+	}
+
+	enc := base64.RawStdEncoding
+	if salt, err = enc.DecodeString(parts[3]); err != nil || len(salt) == 0 {
+		return opt, nil, nil, fmt.Errorf("invalid salt")
+	}
+	if key, err = enc.DecodeString(parts[4]); err != nil || len(key) == 0 {
+		return opt, nil, nil, fmt.Errorf("invalid hash")
+	}
+	opt.SaltLen, opt.KeyLen = len(salt), len(key)
+	if err := opt.check(); err != nil {
+		return opt, nil, nil, err
+	}
+	return opt, salt, key, nil
+}
@@ -0,0 +1,99 @@
+package mencrypt
+
+import (
+	"strings"
+	"testing"
+)
+
+// go test -v -run TestHashPassword
+func TestHashPassword(t *testing.T) {
+	// 测试中使用较小的参数以加快速度
+	opts := []PasswordOption{
+		{Algorithm: PasswordArgon2id, Memory: 1024, Time: 1},
+		{Algorithm: PasswordScrypt, LogN: 10},
+		{Algorithm: PasswordPBKDF2, Iterations: 1000},
+	}
+	prefixes := []string{"$argon2id$v=19$m=1024,t=1,p=1$", "$scrypt$ln=10,r=8,p=1$", "$pbkdf2-sha256$i=1000$"}
+	for i, opt := range opts {
+		h, err := HashPasswordWith("hunter2", opt)
+		if err != nil {
+			t.Fatalf("%s HashPasswordWith error = %v", opt.Algorithm, err)
+		}
+		if !strings.HasPrefix(h, prefixes[i]) {
+			t.Errorf("%s = %s, want prefix %s", opt.Algorithm, h, prefixes[i])
+		}
+		if ok, err := VerifyPassword("hunter2", h); !ok || err != nil {
+			t.Errorf("%s VerifyPassword = %v, %v", opt.Algorithm, ok, err)
+		}
+		if ok, err := VerifyPassword("hunter3", h); ok || err != nil {
+			t.Errorf("%s 错误口令 VerifyPassword = %v, %v", opt.Algorithm, ok, err)
+		}
+		if NeedsRehash(h, opt) {
+			t.Errorf("%s 参数相同时 NeedsRehash = true", opt.Algorithm)
+		}
+		// 与默认参数（argon2id、m=19456,t=2）相比算法或参数不同
+		if !NeedsRehash(h, PasswordOption{}) {
+			t.Errorf("%s 与默认参数不同时 NeedsRehash = false", opt.Algorithm)
+		}
+	}
+
+	h, _ := HashPasswordWith("hunter2", opts[0])
+	if !NeedsRehash(h, PasswordOption{Memory: 2048, Time: 1}) {
+		t.Error("参数升级后 NeedsRehash = false")
+	}
+	if !NeedsRehash("garbage", PasswordOption{}) {
+		t.Error("无法解析时 NeedsRehash = false")
+	}
+
+	// 已知向量：兼容其他实现（如 Python hashlib）生成的哈希
+	for _, known := range []string{
+		"$pbkdf2-sha256$i=1000$c2FsdHNhbHQ$E196ZhRPzw+wA84EjzHwJO1cv/MFJdO6C/sxmUeTYqY",
+		"$scrypt$ln=10,r=8,p=1$c2FsdHNhbHQ$AOLXEESCcPmf2DxU3D47ZJxp5ZTcHC0S2Mb2eFXc4tI",
+	} {
+		if ok, err := VerifyPassword("password", known); !ok || err != nil {
+			t.Errorf("VerifyPassword(%q) = %v, %v", known, ok, err)
+		}
+	}
+
+	for _, bad := range []string{
+		"",
+		"$md5$i=1$c2FsdA$aGFzaA",
+		"$pbkdf2-sha256$i=0$c2FsdA$aGFzaA",
+		"$pbkdf2-sha256$i=99999999$c2FsdA$aGFzaA",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=99999999,t=1,p=1$c2FsdA$aGFzaA",
+		"$scrypt$ln=10,r=8$c2FsdA$aGFzaA",
+		"$scrypt$ln=10,r=8,p=1$!!$aGFzaA",
+		// 未知或重复的参数
+		"$pbkdf2-sha256$i=1000,x=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1,data=1$c2FsdA$aGFzaA",
+		"$scrypt$ln=10,r=8,p=1,p=2$c2FsdA$aGFzaA",
+		"$scrypt$ln=10,n=8,p=1$c2FsdA$aGFzaA",
+		// scrypt 的 r、p、r·p 与内存超出上限
+		"$scrypt$ln=10,r=2048,p=1$c2FsdA$aGFzaA",
+		"$scrypt$ln=10,r=1,p=2048$c2FsdA$aGFzaA",
+		"$scrypt$ln=10,r=64,p=64$c2FsdA$aGFzaA",
+		"$scrypt$ln=20,r=16,p=1$c2FsdA$aGFzaA",
+		// salt 与哈希值过长
+		"$pbkdf2-sha256$i=1000$" + strings.Repeat("A", 88) + "$aGFzaA",
+		"$pbkdf2-sha256$i=1000$c2FsdA$" + strings.Repeat("A", 172),
+	} {
+		if _, err := VerifyPassword("x", bad); err == nil || !strings.HasPrefix(err.Error(), "err:mencrypt.VerifyPassword|parse|") {
+			t.Errorf("VerifyPassword(%q) error = %v", bad, err)
+		}
+	}
+	if _, err := HashPasswordWith("x", PasswordOption{Algorithm: "bcrypt"}); err == nil {
+		t.Error("不支持的算法应返回错误")
+	}
+	// 生成时使用与解析相同的上限
+	for _, opt := range []PasswordOption{
+		{Algorithm: PasswordScrypt, LogN: 20, R: 16},
+		{Algorithm: PasswordScrypt, LogN: 10, R: 64, P: 64},
+		{Algorithm: PasswordPBKDF2, Iterations: 1000, SaltLen: maxSaltLen + 1},
+		{Algorithm: PasswordPBKDF2, Iterations: 1000, KeyLen: maxKeyLen + 1},
+	} {
+		if _, err := HashPasswordWith("x", opt); err == nil {
+			t.Errorf("HashPasswordWith(%+v) 超出上限时应返回错误", opt)
+		}
+	}
+}
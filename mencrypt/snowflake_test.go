@@ -0,0 +1,95 @@
+package mencrypt
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// go test -v -run TestSnowflake
+func TestSnowflake(t *testing.T) {
+	sf, err := NewSnowflake(SnowflakeOption{WorkerID: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now().UnixMilli()
+
+	var (
+		mu   sync.Mutex
+		seen = map[int64]bool{}
+		wg   sync.WaitGroup
+	)
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prev := int64(-1)
+			for i := 0; i < 5000; i++ {
+				id := sf.Next()
+				if id <= prev {
+					t.Errorf("Snowflake 未递增: %d <= %d", id, prev)
+					return
+				}
+				prev = id
+				mu.Lock()
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != 20000 {
+		t.Fatalf("生成了 %d 个不重复的 ID, want 20000", len(seen))
+	}
+
+	id := sf.Next()
+	if SnowflakeWorkerID(id) != 5 || sf.WorkerID() != 5 {
+		t.Errorf("SnowflakeWorkerID = %d, want 5", SnowflakeWorkerID(id))
+	}
+	// 序列号用完时会借用后续毫秒，允许略超前
+	ms := sf.Time(id).ToTime().UnixMilli()
+	if ms < before || ms > time.Now().UnixMilli()+50 {
+		t.Errorf("Time = %d, want between %d and now", ms, before)
+	}
+	if !SnowflakeTime(id, time.Time{}).ToTime().Equal(sf.Time(id).ToTime()) {
+		t.Error("SnowflakeTime 与 Snowflake.Time 结果不同")
+	}
+}
+
+// go test -v -run TestSnowflake_Clock
+func TestSnowflake_Clock(t *testing.T) {
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := epoch.Add(time.Hour)
+	sf, err := NewSnowflake(SnowflakeOption{Epoch: epoch, WorkerID: MaxSnowflakeWorkerID, Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := sf.Next()
+	if want := int64(3600000)<<22 | MaxSnowflakeWorkerID<<12; first != want {
+		t.Fatalf("Next = %d, want %d", first, want)
+	}
+	// 同一毫秒内用完 4096 个序列号后借用下一毫秒
+	var id int64
+	for i := 0; i < 4096; i++ {
+		id = sf.Next()
+	}
+	if got := sf.Time(id).ToTime(); !got.Equal(now.Add(time.Millisecond)) {
+		t.Errorf("序列号用完后 Time = %v, want %v", got, now.Add(time.Millisecond))
+	}
+	// 时钟回拨时不生成更小的 ID
+	now = now.Add(-time.Second)
+	if next := sf.Next(); next <= id {
+		t.Errorf("时钟回拨后 Next = %d, want > %d", next, id)
+	}
+	if got := SnowflakeTime(first, epoch).ToTime(); !got.Equal(epoch.Add(time.Hour)) {
+		t.Errorf("SnowflakeTime = %v", got)
+	}
+
+	if _, err := NewSnowflake(SnowflakeOption{WorkerID: MaxSnowflakeWorkerID + 1}); err == nil {
+		t.Error("WorkerID 超出范围时应返回错误")
+	}
+	if _, err := NewSnowflake(SnowflakeOption{Epoch: time.Now().Add(time.Hour)}); err == nil {
+		t.Error("Epoch 在未来时应返回错误")
+	}
+}
@@ -0,0 +1,120 @@
+package mencrypt
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/m-startgo/go-utils/mtime"
+)
+
+/*
+ULID：48 位毫秒时间戳 + 80 位随机数，编码为 26 个字符的 Crockford base32，
+字典序即时间序，比 UUID 更紧凑，可直接用于 URL。
+
+同一毫秒内生成的 ULID 在上一个的随机部分上加 1，保证同一进程内严格递增；
+系统时钟回拨时沿用上一次的时间戳，不会生成更小的 ULID。
+
+示例：
+
+	id := mencrypt.ULID() // "01ARYZ6S41TSV4RRFFQ69G5FAV"
+	t, _ := mencrypt.ULIDTime(id)
+*/
+
+const (
+	ulidLen      = 26
+	ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+// ulidDecode 是 ulidAlphabet 的反查表，0xFF 表示非法字符，大小写均可。
+var ulidDecode = func() (t [256]byte) {
+	for i := range t {
+		t[i] = 0xFF
+	}
+	for i := 0; i < len(ulidAlphabet); i++ {
+		t[ulidAlphabet[i]] = byte(i)
+		t[ulidAlphabet[i]|0x20] = byte(i) // 小写
+	}
+	return t
+}()
+
+// ulidState 保存上一次生成的 ULID，用于同一毫秒内递增。
+var ulidState struct {
+	mu  sync.Mutex
+	ms  int64
+	rnd [10]byte
+}
+
+// ULID 生成一个单调递增的 ULID。
+func ULID() string {
+	s := &ulidState
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms := time.Now().UnixMilli()
+	if ms > s.ms {
+		s.ms = ms
+		rand.Read(s.rnd[:])
+	} else if !incr(s.rnd[:]) {
+		// 随机部分在同一毫秒内溢出，借用下一毫秒
+		s.ms++
+		rand.Read(s.rnd[:])
+	}
+	return encodeULID(s.ms, s.rnd)
+}
+
+// ULIDTime 返回 ULID 中的毫秒时间戳，时区为 mtime.DefaultLocation。
+func ULIDTime(id string) (mtime.MTime, error) {
+	if len(id) != ulidLen {
+		return mtime.MTime{}, fmt.Errorf("err:mencrypt.ULIDTime|length|want %d characters, got %d", ulidLen, len(id))
+	}
+	// 首字符只有 3 位有效，超过 '7' 会溢出 128 位
+	if ulidDecode[id[0]] > 7 {
+		return mtime.MTime{}, fmt.Errorf("err:mencrypt.ULIDTime|format|invalid ULID %q", id)
+	}
+	var ms int64
+	for i := 0; i < ulidLen; i++ {
+		v := ulidDecode[id[i]]
+		if v == 0xFF {
+			return mtime.MTime{}, fmt.Errorf("err:mencrypt.ULIDTime|format|invalid character %q", id[i])
+		}
+		if i < 10 {
+			ms = ms<<5 | int64(v)
+		}
+	}
+	return idTime(ms), nil
+}
+
+// encodeULID 将 48 位时间戳与 80 位随机数编码为 26 个字符。
+func encodeULID(ms int64, rnd [10]byte) string {
+	var b [16]byte
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(b[6:], rnd[:])
+
+	// 128 位从高到低每 5 位一个字符，首字符只取 3 位
+	var out [ulidLen]byte
+	hi := uint64(b[0])<<56 | uint64(b[1])<<48 | uint64(b[2])<<40 | uint64(b[3])<<32 |
+		uint64(b[4])<<24 | uint64(b[5])<<16 | uint64(b[6])<<8 | uint64(b[7])
+	lo := uint64(b[8])<<56 | uint64(b[9])<<48 | uint64(b[10])<<40 | uint64(b[11])<<32 |
+		uint64(b[12])<<24 | uint64(b[13])<<16 | uint64(b[14])<<8 | uint64(b[15])
+	for i := ulidLen - 1; i >= 0; i-- {
+		out[i] = ulidAlphabet[lo&0x1F]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// incr 将大端字节序的 b 加 1，溢出时返回 false。
+func incr(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package mencrypt
+
+import (
+	"testing"
+	"time"
+)
+
+// go test -v -run TestULID
+func TestULID(t *testing.T) {
+	before := time.Now().UnixMilli()
+	prev := ULID()
+	for i := 0; i < 10000; i++ {
+		id := ULID()
+		if len(id) != 26 {
+			t.Fatalf("ULID = %s, want 26 characters", id)
+		}
+		if id <= prev {
+			t.Fatalf("ULID 未递增: %s <= %s", id, prev)
+		}
+		prev = id
+	}
+
+	ts, err := ULIDTime(prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ms := ts.ToTime().UnixMilli(); ms < before || ms > time.Now().UnixMilli()+1 {
+		t.Errorf("ULIDTime = %d, want between %d and now", ms, before)
+	}
+
+	// ULID 规范中的示例
+	ts, err = ULIDTime("01ARYZ6S41TSV4RRFFQ69G5FAV")
+	if err != nil || ts.ToTime().UnixMilli() != 1469918176385 {
+		t.Errorf("ULIDTime = %v, %v", ts, err)
+	}
+	if ts2, _ := ULIDTime("01aryz6s41tsv4rrffq69g5fav"); !ts2.ToTime().Equal(ts.ToTime()) {
+		t.Error("ULIDTime 应不区分大小写")
+	}
+	for _, bad := range []string{"", "01ARYZ6S41", "81ARYZ6S41TSV4RRFFQ69G5FAV", "01ARYZ6S41TSV4RRFFQ69G5FAU", "01ARYZ6S41TSV4RRFFQ69G5FA!"} {
+		if _, err := ULIDTime(bad); err == nil {
+			t.Errorf("ULIDTime(%q) 应返回错误", bad)
+		}
+	}
+}
+
+// go test -v -run TestEncodeULID
+func TestEncodeULID(t *testing.T) {
+	var rnd [10]byte
+	if got := encodeULID(1469918176385, rnd); got != "01ARYZ6S410000000000000000" {
+		t.Errorf("encodeULID = %s", got)
+	}
+	for i := range rnd {
+		rnd[i] = 0xFF
+	}
+	if got := encodeULID(1<<48-1, rnd); got != "7ZZZZZZZZZZZZZZZZZZZZZZZZZ" {
+		t.Errorf("encodeULID = %s", got)
+	}
+	if incr(rnd[:]) {
+		t.Error("incr 溢出时应返回 false")
+	}
+}
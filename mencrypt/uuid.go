@@ -1,11 +1,14 @@
 package mencrypt
 
 import (
+	"encoding/binary"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/m-startgo/go-utils/mstr"
+	"github.com/m-startgo/go-utils/mtime"
 )
 
 // UUID 生成一个 RFC4122 v4 随机 UUID 并返回字符串表示（小写，带连字符）。
@@ -20,7 +23,33 @@ func UUID() string {
 	return uuid.New().String()
 }
 
+// UUIDv7 生成一个 RFC 9562 v7 UUID，前 48 位为毫秒时间戳，按时间有序，
+// 作为数据库主键时比 v4 更不容易造成索引碎片。同一进程内生成的 UUIDv7 严格递增。
+//
+// 示例：
+//
+//	id := UUIDv7()
+//	// id -> "0190b6a4-2c3e-7d41-9b6a-3f1c2e8d4a10"
+func UUIDv7() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// UUIDv7Time 返回 UUIDv7 中的毫秒时间戳，时区为 mtime.DefaultLocation。
+func UUIDv7Time(id string) (mtime.MTime, error) {
+	u, err := uuid.Parse(id)
+	if err != nil {
+		return mtime.MTime{}, fmt.Errorf("err:mencrypt.UUIDv7Time|parse|%w", err)
+	}
+	if u.Version() != 7 {
+		return mtime.MTime{}, fmt.Errorf("err:mencrypt.UUIDv7Time|version|want version 7, got %d", u.Version())
+	}
+	var b [8]byte
+	copy(b[2:], u[:6])
+	return idTime(int64(binary.BigEndian.Uint64(b[:]))), nil
+}
+
 // 生成一个可读的 Time ID，基于当前时间戳和随机数。
+// 需要紧凑且严格递增的 ID 时请使用 ULID、UUIDv7 或 Snowflake。
 func TimeID() string {
 	t := time.Now().Format("20060102-150405.000")
 	// 去掉.
@@ -28,3 +57,8 @@ func TimeID() string {
 
 	return t + "-" + mstr.Rand(8)
 }
+
+// idTime 将 Unix 毫秒时间戳转换为 mtime.MTime，时区与 mtime 解析数字时间戳时一致。
+func idTime(ms int64) mtime.MTime {
+	return mtime.FromTime(time.UnixMilli(ms).In(mtime.DefaultLocation()))
+}